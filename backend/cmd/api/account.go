@@ -0,0 +1,172 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type deleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Mode     string `json:"mode" binding:"required,oneof=anonymize cascade"`
+}
+
+type accountExport struct {
+	ExportedAt  time.Time         `json:"ExportedAt"`
+	Profile     *database.User    `json:"Profile"`
+	OwnedEvents []*database.Event `json:"OwnedEvents"`
+	Attendances []*database.Event `json:"Attendances"`
+}
+
+func (app *application) deleteCurrentUser(c *gin.Context) {
+	var input deleteAccountRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user := currentUser(c)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		problem(c, http.StatusUnauthorized, "Password is incorrect")
+		return
+	}
+
+	// An organization cannot be left without an owner
+	lastOwner, err := app.models.Organizations.IsLastOwner(user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check organization ownership")
+		return
+	}
+	if lastOwner {
+		respondError(c, database.ErrLastOwner, "Failed to schedule account deletion")
+		return
+	}
+
+	scheduledAt := time.Now().Add(app.deletionGracePeriod)
+	if err := app.models.Users.ScheduleDeletion(user.Id, input.Mode, scheduledAt); err != nil {
+		serverError(c, err, "Failed to schedule account deletion")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"DeletionMode":        input.Mode,
+		"DeletionScheduledAt": scheduledAt,
+	})
+}
+
+func (app *application) cancelAccountDeletion(c *gin.Context) {
+	user := currentUser(c)
+	if user.DeletionScheduledAt == nil {
+		problem(c, http.StatusNotFound, "No deletion is scheduled")
+		return
+	}
+	if err := app.models.Users.CancelDeletion(user.Id); err != nil {
+		serverError(c, err, "Failed to cancel account deletion")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) exportCurrentUser(c *gin.Context) {
+	user := currentUser(c)
+
+	owned, err := app.models.Events.GetByOwner(user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve owned events")
+		return
+	}
+	attending, err := app.models.Attendees.GetEventsByAttendee(user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve attendances")
+		return
+	}
+	if attending == nil {
+		attending = []*database.Event{}
+	}
+
+	export := accountExport{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     user,
+		OwnedEvents: owned,
+		Attendances: attending,
+	}
+
+	filename := fmt.Sprintf("account-export-%d-%s", user.Id, export.ExportedAt.Format("20060102"))
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+	case "zip":
+		archive, err := buildExportArchive(export)
+		if err != nil {
+			serverError(c, err, "Failed to build export archive")
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+		c.Data(http.StatusOK, "application/zip", archive)
+	default:
+		problem(c, http.StatusBadRequest, "format must be json or zip")
+	}
+}
+
+// buildExportArchive writes each section of the export to its own file in a ZIP
+func buildExportArchive(export accountExport) ([]byte, error) {
+	files := map[string]interface{}{
+		"export.json":       export,
+		"profile.json":      export.Profile,
+		"owned_events.json": export.OwnedEvents,
+		"attendances.json":  export.Attendances,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// processAccountDeletions carries out deletions whose grace period has
+// expired. A failing account is retried on the next run without holding up
+// the others.
+func (app *application) processAccountDeletions() error {
+	users, err := app.models.Users.GetDueForDeletion()
+	if err != nil {
+		return err
+	}
+	var failures []error
+	for _, user := range users {
+		switch user.DeletionMode {
+		case database.DeletionModeCascade:
+			err = app.models.Users.Delete(user.Id)
+		default:
+			err = app.models.Users.Anonymize(user.Id)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("user %d: %w", user.Id, err))
+			continue
+		}
+		log.Printf("🗑️ Processed %s deletion for user %d\n", user.DeletionMode, user.Id)
+	}
+	return errors.Join(failures...)
+}
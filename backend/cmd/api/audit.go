@@ -0,0 +1,139 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+// audit appends an entry to the audit log, filling in the actor and request
+// id from c when there is one (background jobs pass nil). Failures are
+// logged since the change itself has been saved.
+func (app *application) audit(c *gin.Context, entry database.AuditEntry) {
+	if c != nil {
+		if user := currentUser(c); user != nil {
+			entry.ActorId = &user.Id
+		}
+		entry.RequestId = currentRequestId(c)
+	}
+	if err := app.models.Audit.Insert(&entry); err != nil {
+		log.Printf("❌ Could not record %s of %s %d: %v\n", entry.Action, entry.EntityType, entry.EntityId, err)
+	}
+}
+
+// auditEvent records a change to an event. before is nil for creations and
+// after for deletions; updates that changed nothing are skipped.
+func (app *application) auditEvent(c *gin.Context, action string, before, after *database.Event) {
+	event := after
+	if event == nil {
+		event = before
+	}
+	changes := database.Diff(before, after)
+	if len(changes) == 0 && action == database.AuditActionUpdate {
+		return
+	}
+	app.audit(c, database.AuditEntry{
+		OrganizationId: event.OrganizationId,
+		EventId:        &event.Id,
+		EntityType:     database.AuditEntityEvent,
+		EntityId:       event.Id,
+		Action:         action,
+		Changes:        changes,
+	})
+}
+
+// auditAttendee records a change to a user's registration for an event
+func (app *application) auditAttendee(c *gin.Context, action string, event *database.Event, userId int, changes map[string]database.FieldChange) {
+	app.audit(c, database.AuditEntry{
+		OrganizationId: event.OrganizationId,
+		EventId:        &event.Id,
+		EntityType:     database.AuditEntityAttendee,
+		EntityId:       userId,
+		Action:         action,
+		Changes:        changes,
+	})
+}
+
+func (app *application) getEventHistory(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	entries, err := app.models.Audit.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve event history")
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// getAuditLog pages through the organization's audit log. Pass the last
+// Id seen as ?before= to fetch the next page.
+func (app *application) getAuditLog(c *gin.Context) {
+	if !database.CanManage(currentOrgRole(c)) {
+		problem(c, http.StatusForbidden, "Only organization admins can view the audit log")
+		return
+	}
+
+	filter := database.AuditFilter{
+		EntityType: c.Query("entityType"),
+		Action:     c.Query("action"),
+		Limit:      defaultAuditLimit,
+	}
+	if filter.EntityType != "" && filter.EntityType != database.AuditEntityEvent && filter.EntityType != database.AuditEntityAttendee {
+		problem(c, http.StatusBadRequest, "entityType must be event or attendee")
+		return
+	}
+	for name, target := range map[string]**int{"eventId": &filter.EventId, "actorId": &filter.ActorId} {
+		if v := c.Query(name); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				problem(c, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			*target = &id
+		}
+	}
+	for name, target := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				problem(c, http.StatusBadRequest, name+" must be an RFC 3339 timestamp")
+				return
+			}
+			*target = &t
+		}
+	}
+	if v := c.Query("before"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			problem(c, http.StatusBadRequest, "Invalid before")
+			return
+		}
+		filter.BeforeId = id
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			problem(c, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxAuditLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := app.models.Audit.Query(currentOrgId(c), filter)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve audit log")
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -33,22 +33,21 @@ type userResponse struct {
 }
 func (app *application) login(c *gin.Context) {
 	var auth loginRequest
-	if err := c.ShouldBindJSON(&auth); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &auth) {
 		return
 	}
-	existingUser, err := app.models.Users.GetByEmail(auth.Email)
-	if existingUser == nil  {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	existingUser, err := app.models.Users.GetByEmail(normalizeEmail(auth.Email))
+	if err != nil {
+		serverError(c, err, "Failed to log in")
 		return
 	}
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if existingUser == nil {
+		problem(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 	err = bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(auth.Password))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error":err.Error()})
+		problem(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 	token :=jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
@@ -57,7 +56,7 @@ func (app *application) login(c *gin.Context) {
 	})
 tokenString ,err := token.SignedString([]byte(app.jwtSecret))
 if err != nil {
-	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	serverError(c, err, "Failed to generate token")
 	return
 }
 c.JSON(http.StatusOK, loginResponse{
@@ -75,25 +74,30 @@ c.JSON(http.StatusOK, loginResponse{
 
 func (app *application) registerUser(c *gin.Context) {
 	var register registerRequest
-	if err := c.ShouldBindJSON(&register); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &register) {
 		return
 	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(register.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		serverError(c, err, "Failed to hash password")
 		return
 	}
 	register.Password = string(hashedPassword)
 	user := database.User{
-		Email:    register.Email,
+		Email:    normalizeEmail(register.Email),
 		Password: register.Password,
 		Name :	register.Name,
 	
 	}
-	err = app.models.Users.Insert(&user)
+	// Everyone joins the shared default organization; only the account named
+	// by DEFAULT_ORG_OWNER_EMAIL owns it
+	role := database.OrgRoleMember
+	if app.defaultOrgOwner != "" && user.Email == app.defaultOrgOwner {
+		role = database.OrgRoleOwner
+	}
+	err = app.models.Users.Insert(&user, role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, "Failed to create user")
 		return
 	}
 	c.JSON(http.StatusCreated,user)
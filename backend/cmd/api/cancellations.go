@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/payments"
+
+	"github.com/gin-gonic/gin"
+)
+
+type cancelRegistrationRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type transferTicketRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Note  string `json:"note" binding:"max=500"`
+}
+
+// refundOwed works out what goes back to the buyer when a registration is
+// given up. Organizer removals refund in full; attendees get the event's
+// refund share until the cutoff, and nothing after it.
+func (app *application) refundOwed(event *database.Event, order *database.Order, full bool) (int64, error) {
+	if order == nil || order.Status != database.OrderStatusPaid || order.PaymentReference == "" {
+		return 0, nil
+	}
+	refundable := order.TotalCents - order.RefundedCents
+	if refundable <= 0 {
+		return 0, nil
+	}
+	if full {
+		return refundable, nil
+	}
+
+	open, err := app.models.Events.RefundWindowOpen(event.Id)
+	if err != nil || !open {
+		return 0, err
+	}
+	return refundable * int64(event.RefundPercent) / 100, nil
+}
+
+// cancelAttendee refunds what is owed and removes the registration. It writes
+// an error response and returns nil when something goes wrong.
+func (app *application) cancelAttendee(c *gin.Context, event *database.Event, attendee *database.Attendee, action, note string) *database.TicketHistoryEntry {
+	if attendee.CheckedInAt != nil {
+		problem(c, http.StatusConflict, "Checked-in registrations cannot be cancelled")
+		return nil
+	}
+
+	var order *database.Order
+	if attendee.OrderId != nil {
+		var err error
+		order, err = app.models.Orders.Get(*attendee.OrderId)
+		if err != nil {
+			serverError(c, err, "Failed to retrieve order")
+			return nil
+		}
+	}
+
+	// Organizers can remove someone without refunding, e.g. for a policy breach
+	full := action == database.TicketActionRemoved
+	if full && c.Query("refund") == "false" {
+		order = nil
+	}
+	amount, err := app.refundOwed(event, order, full)
+	if err != nil {
+		serverError(c, err, "Failed to work out refund")
+		return nil
+	}
+
+	entry := database.TicketHistoryEntry{
+		Action:      action,
+		ActorId:     &currentUser(c).Id,
+		RefundCents: amount,
+		Note:        note,
+	}
+	if amount > 0 {
+		entry.RefundReference, err = app.payments.Refund(c.Request.Context(), payments.RefundRequest{
+			Reference:   order.PaymentReference,
+			AmountCents: amount,
+			// One refund per registration, however often the request is retried
+			IdempotencyKey: fmt.Sprintf("refund-attendee-%d", attendee.Id),
+		})
+		if err != nil {
+			log.Printf("❌ Refund for order %d failed: %v\n", order.Id, err)
+			problem(c, http.StatusBadGateway, "Payment provider could not issue the refund, please try again")
+			return nil
+		}
+	}
+
+	err = app.models.TicketHistory.Cancel(attendee, &entry)
+	if err != nil {
+		if entry.RefundReference != "" {
+			log.Printf("⚠️ Refund %s issued but attendee %d was not cancelled: %v\n", entry.RefundReference, attendee.Id, err)
+		}
+		respondError(c, err, "Failed to cancel registration")
+		return nil
+	}
+	app.auditAttendee(c, database.AuditActionDelete, event, attendee.UserId, database.Diff(attendee, nil))
+	return &entry
+}
+
+func (app *application) cancelMyRegistration(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+
+	var input cancelRegistrationRequest
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registration")
+		return
+	}
+	if attendee == nil {
+		problem(c, http.StatusNotFound, "You are not registered for this event")
+		return
+	}
+
+	entry := app.cancelAttendee(c, event, attendee, database.TicketActionCancelled, input.Reason)
+	if entry == nil {
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (app *application) transferMyTicket(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	user := currentUser(c)
+
+	var input transferTicketRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registration")
+		return
+	}
+	if attendee == nil {
+		problem(c, http.StatusNotFound, "You are not registered for this event")
+		return
+	}
+	if attendee.Status != database.AttendeeStatusConfirmed {
+		problem(c, http.StatusConflict, "Only confirmed registrations can be transferred")
+		return
+	}
+
+	recipient, err := app.models.Users.GetByEmail(normalizeEmail(input.Email))
+	if err != nil {
+		serverError(c, err, "Failed to look up recipient")
+		return
+	}
+	if recipient == nil {
+		problem(c, http.StatusNotFound, "No account uses that email address")
+		return
+	}
+	if recipient.Id == user.Id {
+		problem(c, http.StatusBadRequest, "You already hold this ticket")
+		return
+	}
+
+	entry := database.TicketHistoryEntry{ActorId: &user.Id, Note: input.Note}
+	transferred, err := app.models.TicketHistory.Transfer(attendee, recipient.Id, &entry)
+	if err != nil {
+		respondError(c, err, "Failed to transfer ticket")
+		return
+	}
+
+	app.auditAttendee(c, database.AuditActionTransfer, event, user.Id, map[string]database.FieldChange{
+		"UserId": {From: user.Id, To: recipient.Id},
+	})
+
+	body := fmt.Sprintf("%s has transferred their ticket for %s (%s, %s) to you.\n\nYour ticket is available in the app.",
+		user.Name, event.Name, event.DateTime, event.Location)
+	if input.Note != "" {
+		body += "\n\nTheir note: " + input.Note
+	}
+	if err := app.mailer.Send(recipient.Email, "You've received a ticket: "+event.Name, body); err != nil {
+		log.Printf("❌ Could not send transfer notice to user %d: %v\n", recipient.Id, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"Attendee": transferred, "History": entry})
+}
+
+func (app *application) getEventTicketHistory(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	entries, err := app.models.TicketHistory.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve ticket history")
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+func (app *application) getMyTicketHistory(c *gin.Context) {
+	entries, err := app.models.TicketHistory.GetByUser(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve ticket history")
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
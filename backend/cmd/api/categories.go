@@ -0,0 +1,213 @@
+package main
+
+import (
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTagSuggestions = 10
+	maxTagSuggestions     = 50
+)
+
+type categoryRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+	// Derived from the name when left out
+	Slug        string `json:"slug" binding:"max=100"`
+	Description string `json:"description" binding:"max=2000"`
+	Position    int    `json:"position" binding:"min=0"`
+}
+
+func (r *categoryRequest) applyTo(category *database.Category) {
+	category.Name = strings.TrimSpace(r.Name)
+	category.Slug = slugify(r.Slug)
+	if category.Slug == "" {
+		category.Slug = slugify(r.Name)
+	}
+	category.Description = strings.TrimSpace(r.Description)
+	category.Position = r.Position
+}
+
+// slugify lowercases s and joins its words with hyphens, so "Live Music!"
+// becomes live-music
+func slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// normalizeTags slugifies each tag and drops empty and repeated ones,
+// keeping the first occurrence's position
+func normalizeTags(raw []string) []string {
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]bool)
+	for _, t := range raw {
+		tag := slugify(t)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// checkCategory makes sure the event's category belongs to its
+// organization. It writes a 422 and returns false when it does not.
+func (app *application) checkCategory(c *gin.Context, event *database.Event) bool {
+	if event.CategoryId == nil {
+		return true
+	}
+	category, err := app.models.Categories.Get(event.OrganizationId, *event.CategoryId)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve category")
+		return false
+	}
+	if category == nil {
+		validationFailed(c, "Invalid event", fieldError{Field: "categoryId", Code: "reference", Message: "no such category in this organization"})
+		return false
+	}
+	return true
+}
+
+// requireCategoryManager lets only organization admins shape the taxonomy
+func requireCategoryManager(c *gin.Context) bool {
+	if !database.CanManage(currentOrgRole(c)) {
+		problem(c, http.StatusForbidden, "Only organization admins can manage categories")
+		return false
+	}
+	return true
+}
+
+// loadCategory parses :categoryId within the current organization
+func (app *application) loadCategory(c *gin.Context) *database.Category {
+	id, err := strconv.Atoi(c.Param("categoryId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid category ID")
+		return nil
+	}
+	category, err := app.models.Categories.Get(currentOrgId(c), id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve category")
+		return nil
+	}
+	if category == nil {
+		problem(c, http.StatusNotFound, "Category not found")
+		return nil
+	}
+	return category
+}
+
+// getCategories lists the organization's categories for browsing, each with
+// how many events the caller can find in it, optionally only in the states
+// given by ?status=
+func (app *application) getCategories(c *gin.Context) {
+	statuses, ok := parseStatuses(c)
+	if !ok {
+		return
+	}
+	categories, err := app.models.Categories.GetByOrganization(currentOrgId(c), viewerId(c), statuses, database.CanManage(currentOrgRole(c)))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve categories")
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+func (app *application) createCategory(c *gin.Context) {
+	if !requireCategoryManager(c) {
+		return
+	}
+	var input categoryRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	category := database.Category{OrganizationId: currentOrgId(c)}
+	input.applyTo(&category)
+	if category.Slug == "" {
+		validationFailed(c, "Invalid category", fieldError{Field: "slug", Code: "required", Message: "must contain letters or digits"})
+		return
+	}
+
+	if err := app.models.Categories.Insert(&category); err != nil {
+		respondError(c, err, "Failed to create category")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+func (app *application) updateCategory(c *gin.Context) {
+	if !requireCategoryManager(c) {
+		return
+	}
+	category := app.loadCategory(c)
+	if category == nil {
+		return
+	}
+	var input categoryRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	input.applyTo(category)
+	if category.Slug == "" {
+		validationFailed(c, "Invalid category", fieldError{Field: "slug", Code: "required", Message: "must contain letters or digits"})
+		return
+	}
+
+	if err := app.models.Categories.Update(category); err != nil {
+		respondError(c, err, "Failed to update category")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// deleteCategory removes a category, leaving its events uncategorized
+func (app *application) deleteCategory(c *gin.Context) {
+	if !requireCategoryManager(c) {
+		return
+	}
+	category := app.loadCategory(c)
+	if category == nil {
+		return
+	}
+	if err := app.models.Categories.Delete(category.OrganizationId, category.Id); err != nil {
+		serverError(c, err, "Failed to delete category")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// suggestTags autocompletes ?q= against the tags on events the caller can
+// see, most used first. Without q it lists the most used tags.
+func (app *application) suggestTags(c *gin.Context) {
+	limit := defaultTagSuggestions
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTagSuggestions {
+			problem(c, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxTagSuggestions))
+			return
+		}
+		limit = n
+	}
+
+	// Keep a trailing separator so "live " suggests live-music but not lively
+	q := c.Query("q")
+	prefix := slugify(q)
+	if prefix != "" && strings.TrimRightFunc(q, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) != q {
+		prefix += "-"
+	}
+
+	tags, err := app.models.Events.SuggestTags(currentOrgId(c), viewerId(c), prefix, database.CanManage(currentOrgRole(c)), limit)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve tags")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
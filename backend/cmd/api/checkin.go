@@ -0,0 +1,156 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/tickets"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const checkInStatsInterval = 2 * time.Second
+
+type checkInRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (app *application) getMyTicket(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registration")
+		return
+	}
+	if attendee == nil || attendee.Status != database.AttendeeStatusConfirmed {
+		problem(c, http.StatusNotFound, "You do not have a confirmed ticket for this event")
+		return
+	}
+
+	code := tickets.Sign(app.ticketSecret, attendee.Id, event.Id)
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{"EventId": event.Id, "AttendeeId": attendee.Id, "Code": code})
+	case "png":
+		png, err := tickets.QRCodePNG(code, 512)
+		if err != nil {
+			serverError(c, err, "Failed to render ticket")
+			return
+		}
+		c.Data(http.StatusOK, "image/png", png)
+	case "svg":
+		svg, err := tickets.QRCodeSVG(code)
+		if err != nil {
+			serverError(c, err, "Failed to render ticket")
+			return
+		}
+		c.Data(http.StatusOK, "image/svg+xml", svg)
+	default:
+		problem(c, http.StatusBadRequest, "format must be json, png or svg")
+	}
+}
+
+func (app *application) checkInAttendee(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionCheckIn) {
+		return
+	}
+
+	var input checkInRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	staffId := currentUser(c).Id
+	attendeeId, eventId, err := tickets.Verify(app.ticketSecret, input.Code)
+	if err != nil || eventId != event.Id {
+		if err := app.models.Attendees.RecordInvalidScan(event.Id, staffId); err != nil {
+			serverError(c, err, "Failed to record scan")
+			return
+		}
+		problem(c, http.StatusUnprocessableEntity, "Ticket is not valid for this event")
+		return
+	}
+
+	result, err := app.models.Attendees.CheckIn(event.Id, attendeeId, staffId)
+	if err != nil {
+		serverError(c, err, "Failed to check in attendee")
+		return
+	}
+	if result == nil {
+		problem(c, http.StatusNotFound, "Registration no longer exists")
+		return
+	}
+	if result.Status != database.AttendeeStatusConfirmed {
+		problem(c, http.StatusUnprocessableEntity, "Registration is "+result.Status, gin.H{"Attendee": result})
+		return
+	}
+	if result.Duplicate {
+		problem(c, http.StatusConflict, "Ticket was already checked in", gin.H{"Attendee": result})
+		return
+	}
+	app.auditAttendee(c, database.AuditActionCheckIn, event, result.UserId, map[string]database.FieldChange{
+		"CheckedInAt": {From: nil, To: result.CheckedInAt},
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+func (app *application) getCheckInStats(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionCheckIn) {
+		return
+	}
+	stats, err := app.models.Attendees.GetCheckInStats(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve check-in stats")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// streamCheckInStats pushes the stats as server-sent events whenever they change
+func (app *application) streamCheckInStats(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionCheckIn) {
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		serverError(c, err, "Streaming is not supported")
+		return
+	}
+
+	ticker := time.NewTicker(checkInStatsInterval)
+	defer ticker.Stop()
+
+	var last database.CheckInStats
+	first := true
+	c.Stream(func(w io.Writer) bool {
+		if !first {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-ticker.C:
+			}
+		}
+		stats, err := app.models.Attendees.GetCheckInStats(event.Id)
+		if err != nil {
+			log.Printf("❌ [%s] Check-in stats for event %d: %v\n", currentRequestId(c), event.Id, err)
+			c.SSEvent("error", "failed to retrieve check-in stats")
+			return false
+		}
+		if first || *stats != last {
+			c.SSEvent("stats", stats)
+			last = *stats
+			first = false
+		}
+		return true
+	})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Problem types are stable identifiers clients can switch on. They are
+// relative URIs, resolved against the API's own address.
+const problemTypeBase = "/problems/"
+
+// apiError is an error on its way to becoming an RFC 7807 problem+json
+// response. Err is the internal cause: it is logged, never sent.
+type apiError struct {
+	Status int
+	// Slug under problemTypeBase; the status decides when empty
+	Type       string
+	Detail     string
+	Err        error
+	Extensions gin.H
+}
+
+func (e *apiError) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *apiError) Unwrap() error {
+	return e.Err
+}
+
+// statusProblemTypes names the problem type of each status used without a
+// more specific one
+var statusProblemTypes = map[int]string{
+	http.StatusBadRequest:           "bad-request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusPaymentRequired:      "payment-required",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not-found",
+	http.StatusMethodNotAllowed:     "method-not-allowed",
+	http.StatusConflict:             "conflict",
+	http.StatusGone:                 "gone",
+	http.StatusPreconditionFailed:   "precondition-failed",
+	http.StatusUnsupportedMediaType: "unsupported-media-type",
+	http.StatusUnprocessableEntity:  "validation-error",
+	http.StatusPreconditionRequired: "precondition-required",
+	http.StatusInternalServerError:  "internal-error",
+	http.StatusBadGateway:           "bad-gateway",
+}
+
+// domainErrors maps the database package's errors to the responses they
+// deserve wherever they surface
+var domainErrors = []apiError{
+	{Err: database.ErrEventFull, Status: http.StatusConflict, Type: "event-full", Detail: "Event is full"},
+	{Err: database.ErrEventClosed, Status: http.StatusConflict, Type: "event-closed", Detail: "Event is not open for registration"},
+	{Err: database.ErrEditConflict, Status: http.StatusConflict, Type: "edit-conflict", Detail: "Event was changed in the meantime; reload and try again"},
+	{Err: database.ErrAlreadyCheckedIn, Status: http.StatusConflict, Type: "already-checked-in", Detail: "Attendee has already checked in"},
+	{Err: database.ErrAlreadyRegistered, Status: http.StatusConflict, Type: "already-registered", Detail: "User is already registered for this event"},
+	{Err: database.ErrLastOwner, Status: http.StatusConflict, Type: "last-owner", Detail: "You are the only owner of an organization; hand ownership to someone else first"},
+	{Err: database.ErrNotRestorable, Status: http.StatusConflict, Type: "not-restorable", Detail: "Registration was refunded, transferred or belongs to a deleted user"},
+	{Err: database.ErrInvitationUnusable, Status: http.StatusGone, Type: "invitation-unusable", Detail: "Invitation is expired, revoked or used up"},
+	{Err: database.ErrPromoCodeInUse, Status: http.StatusConflict, Type: "promo-code-in-use", Detail: "Promo code has been redeemed; deactivate it instead"},
+	{Err: database.ErrTicketTypeInUse, Status: http.StatusConflict, Type: "ticket-type-in-use", Detail: "Ticket type has orders; set its quantity to 0 instead"},
+	{Err: database.ErrQuestionTypeLocked, Status: http.StatusConflict, Type: "question-type-locked", Detail: "A question's type cannot change once it has answers; add a new question instead"},
+	{Err: database.ErrRoomBooked, Status: http.StatusConflict, Type: "room-double-booked", Detail: "Room is already booked at that time"},
+	{Err: database.ErrVenueInUse, Status: http.StatusConflict, Type: "venue-in-use", Detail: "Venue has upcoming events; move them first"},
+	{Err: database.ErrRoomInUse, Status: http.StatusConflict, Type: "room-in-use", Detail: "Room has upcoming events; move them first"},
+}
+
+// constraintProblems describes each kind of constraint violation. The
+// offending fields are listed with the code given here.
+var constraintProblems = map[error]struct {
+	status    int
+	problem   string
+	fieldCode string
+}{
+	database.ErrDuplicate:        {http.StatusConflict, "duplicate", "unique"},
+	database.ErrInvalidReference: {http.StatusUnprocessableEntity, "invalid-reference", "reference"},
+	database.ErrCheckViolation:   {http.StatusUnprocessableEntity, "validation-error", "check"},
+	database.ErrMissingValue:     {http.StatusUnprocessableEntity, "validation-error", "required"},
+	database.ErrOverlap:          {http.StatusConflict, "overlap", "overlap"},
+}
+
+// Columns whose request field is not simply their camelCase form
+var columnFields = map[string]string{
+	"datetime": "dateTime",
+}
+
+// columnField names a database column the way request bodies do
+func columnField(column string) string {
+	if field, ok := columnFields[column]; ok {
+		return field
+	}
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// constraintError reports a write the database refused, naming the fields
+// involved but not the table or constraint behind them
+func constraintError(e *database.ConstraintError) *apiError {
+	kind := constraintProblems[e.Kind]
+	fields := make([]fieldError, 0, len(e.Columns))
+	names := make([]string, 0, len(e.Columns))
+	for _, column := range e.Columns {
+		field := columnField(column)
+		fields = append(fields, fieldError{Field: field, Code: kind.fieldCode, Message: e.Kind.Error()})
+		names = append(names, field)
+	}
+
+	detail := strings.ToUpper(e.Kind.Error()[:1]) + e.Kind.Error()[1:]
+	if len(names) > 0 {
+		detail = strings.Join(names, ", ") + ": " + e.Kind.Error()
+	}
+	return &apiError{
+		Status:     kind.status,
+		Type:       kind.problem,
+		Detail:     detail,
+		Err:        e,
+		Extensions: gin.H{"fields": fields},
+	}
+}
+
+// asAPIError decides how err is reported. apiErrors pass through, known
+// domain errors and constraint violations get their own status and anything
+// else is a 500 with the given detail.
+func asAPIError(err error, detail string) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	for _, known := range domainErrors {
+		if errors.Is(err, known.Err) {
+			mapped := known
+			mapped.Err = err
+			return &mapped
+		}
+	}
+	var constraintErr *database.ConstraintError
+	if errors.As(err, &constraintErr) {
+		return constraintError(constraintErr)
+	}
+	return &apiError{Status: http.StatusInternalServerError, Detail: detail, Err: err}
+}
+
+// writeProblem sends e as application/problem+json. Server errors are
+// logged with their cause and request id so they can be traced.
+func writeProblem(c *gin.Context, e *apiError) {
+	requestId := currentRequestId(c)
+	if e.Status >= http.StatusInternalServerError {
+		log.Printf("❌ [%s] %s %s: %v\n", requestId, c.Request.Method, c.Request.URL.Path, e)
+	}
+
+	problemType := e.Type
+	if problemType == "" {
+		problemType = statusProblemTypes[e.Status]
+	}
+	body := gin.H{
+		"type":     problemTypeBase + problemType,
+		"title":    http.StatusText(e.Status),
+		"status":   e.Status,
+		"detail":   e.Detail,
+		"instance": c.Request.URL.Path,
+	}
+	if requestId != "" {
+		body["requestId"] = requestId
+	}
+	for k, v := range e.Extensions {
+		if _, reserved := body[k]; !reserved {
+			body[k] = v
+		}
+	}
+
+	c.Header("Content-Type", "application/problem+json")
+	c.JSON(e.Status, body)
+}
+
+// problem responds with a status and a message meant for the client.
+// extensions add members such as the conflicting resource.
+func problem(c *gin.Context, status int, detail string, extensions ...gin.H) {
+	e := &apiError{Status: status, Detail: detail}
+	if len(extensions) > 0 {
+		e.Extensions = extensions[0]
+	}
+	writeProblem(c, e)
+}
+
+// serverError logs err and responds with a 500 that reveals only detail
+func serverError(c *gin.Context, err error, detail string) {
+	writeProblem(c, &apiError{Status: http.StatusInternalServerError, Detail: detail, Err: err})
+}
+
+// respondError reports err, mapping known domain errors to their own
+// responses and anything else to a 500 with the given detail
+func respondError(c *gin.Context, err error, detail string) {
+	writeProblem(c, asAPIError(err, detail))
+}
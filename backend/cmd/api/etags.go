@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventETag is the entity tag for the event's current version
+func eventETag(event *database.Event) string {
+	return `"` + strconv.Itoa(event.Version) + `"`
+}
+
+// etagListed reports whether a comma-separated If-Match or If-None-Match
+// header names the tag. Weak tags only count when weak is set, as for
+// If-None-Match.
+func etagListed(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified answers 304 when the client's If-None-Match still matches the
+// event. Otherwise it sets the ETag and returns false.
+func notModified(c *gin.Context, event *database.Event) bool {
+	etag := eventETag(event)
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagListed(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// requireIfMatch makes writes conditional on the version the client last
+// saw. It writes a 428 when If-Match is missing and a 412 when the event has
+// moved on, and returns false in both cases.
+func requireIfMatch(c *gin.Context, event *database.Event) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		problem(c, http.StatusPreconditionRequired, "If-Match header with the event's ETag is required")
+		return false
+	}
+	if !etagListed(header, eventETag(event), false) {
+		c.Header("ETag", eventETag(event))
+		editConflict(c)
+		return false
+	}
+	// A wildcard matches whatever version is current
+	return true
+}
+
+// editConflict reports a write based on a stale version
+func editConflict(c *gin.Context) {
+	problem(c, http.StatusPreconditionFailed, "Event has been modified since it was loaded; reload and try again")
+}
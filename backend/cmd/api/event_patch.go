@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/jsonpatch"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// eventDocument is the writable part of an event, named as in the event's
+// JSON. Patches are applied to it and the result must still decode into it.
+type eventDocument struct {
+	Name              string     `json:"Name" binding:"required,min=3,max=255"`
+	Description       string     `json:"Description" binding:"required,min=10,max=10000"`
+	DateTime          string     `json:"DateTime" binding:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	Location          string     `json:"Location" binding:"required_without=VenueId,omitempty,min=3,max=500"`
+	Visibility        string     `json:"Visibility" binding:"required,oneof=public unlisted private"`
+	RequiresApproval  bool       `json:"RequiresApproval"`
+	Capacity          *int       `json:"Capacity" binding:"omitempty,min=1"`
+	MaxGuests         int        `json:"MaxGuests" binding:"min=0,max=100"`
+	RefundPercent     int        `json:"RefundPercent" binding:"min=0,max=100"`
+	RefundCutoffHours int        `json:"RefundCutoffHours" binding:"min=0"`
+	PublishAt         *time.Time `json:"PublishAt" binding:"omitempty,future"`
+	// Set both to null to have them looked up again
+	Latitude  *float64          `json:"Latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64          `json:"Longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	Address   *database.Address `json:"Address"`
+	VenueId   *int              `json:"VenueId" binding:"omitempty,min=1"`
+	RoomId    *int              `json:"RoomId" binding:"omitempty,min=1"`
+	EndsAt    *time.Time        `json:"EndsAt"`
+
+	CategoryId *int     `json:"CategoryId" binding:"omitempty,min=1"`
+	Tags       []string `json:"Tags" binding:"max=10,dive,min=1,max=50"`
+}
+
+func newEventDocument(event *database.Event) eventDocument {
+	return eventDocument{
+		Name:              event.Name,
+		Description:       event.Description,
+		DateTime:          event.DateTime,
+		Location:          event.Location,
+		Visibility:        event.Visibility,
+		RequiresApproval:  event.RequiresApproval,
+		Capacity:          event.Capacity,
+		MaxGuests:         event.MaxGuests,
+		RefundPercent:     event.RefundPercent,
+		RefundCutoffHours: event.RefundCutoffHours,
+		PublishAt:         event.PublishAt,
+		Latitude:          event.Latitude,
+		Longitude:         event.Longitude,
+		Address:           event.Address,
+		VenueId:           event.VenueId,
+		RoomId:            event.RoomId,
+		EndsAt:            event.EndsAt,
+		CategoryId:        event.CategoryId,
+		Tags:              event.Tags,
+	}
+}
+
+func (d eventDocument) applyTo(event *database.Event) {
+	event.Name = d.Name
+	event.Description = d.Description
+	event.DateTime = d.DateTime
+	if t, err := time.Parse(time.RFC3339, d.DateTime); err == nil {
+		// Stored without a zone, so normalize to UTC
+		event.DateTime = t.UTC().Format(time.RFC3339)
+	}
+	event.Location = d.Location
+	event.Visibility = d.Visibility
+	event.RequiresApproval = d.RequiresApproval
+	event.Capacity = d.Capacity
+	event.MaxGuests = d.MaxGuests
+	event.RefundPercent = d.RefundPercent
+	event.RefundCutoffHours = d.RefundCutoffHours
+	event.PublishAt = d.PublishAt
+	event.Latitude = d.Latitude
+	event.Longitude = d.Longitude
+	event.Address = d.Address
+	if d.Address != nil && *d.Address == (database.Address{}) {
+		event.Address = nil
+	}
+	event.VenueId = d.VenueId
+	event.RoomId = d.RoomId
+	event.EndsAt = nil
+	if d.EndsAt != nil {
+		endsAt := d.EndsAt.UTC()
+		event.EndsAt = &endsAt
+	}
+	event.CategoryId = d.CategoryId
+	event.Tags = normalizeTags(d.Tags)
+}
+
+// patchEvent changes part of an event with a JSON Merge Patch (RFC 7396) or
+// a JSON Patch (RFC 6902). Merge patches clear a field with null; removing a
+// field with JSON Patch resets it to its zero value.
+func (app *application) patchEvent(c *gin.Context) {
+	existingEvent := app.loadEvent(c)
+	if existingEvent == nil {
+		return
+	}
+	if !app.requireEventPermission(c, existingEvent, database.PermissionEditDetails) || !requireIfMatch(c, existingEvent) {
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if contentType != mergePatchContentType && contentType != jsonPatchContentType {
+		c.Header("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		problem(c, http.StatusUnsupportedMediaType, "Content-Type must be "+mergePatchContentType+" or "+jsonPatchContentType)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	// Round-trip through JSON so the patch sees the same types a client would
+	var doc interface{}
+	encoded, err := json.Marshal(newEventDocument(existingEvent))
+	if err == nil {
+		err = json.Unmarshal(encoded, &doc)
+	}
+	if err != nil {
+		serverError(c, err, "Failed to prepare event for patching")
+		return
+	}
+
+	var patched interface{}
+	if contentType == mergePatchContentType {
+		var patch interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			problem(c, http.StatusBadRequest, "Invalid merge patch: "+err.Error())
+			return
+		}
+		patched = jsonpatch.MergePatch(doc, patch)
+	} else {
+		var ops []jsonpatch.Operation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			problem(c, http.StatusBadRequest, "Invalid JSON Patch: "+err.Error())
+			return
+		}
+		patched, err = jsonpatch.Apply(doc, ops)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			problem(c, http.StatusConflict, "A test operation did not match the event")
+			return
+		}
+		if err != nil {
+			problem(c, http.StatusUnprocessableEntity, "Patch could not be applied: "+err.Error())
+			return
+		}
+	}
+
+	result, fields := decodeEventDocument(patched)
+	if fields != nil {
+		validationFailed(c, "Patched event is invalid", fields...)
+		return
+	}
+	if !validateRequest(c, result) {
+		return
+	}
+
+	updatedEvent := editableCopy(existingEvent)
+	result.applyTo(updatedEvent)
+	app.saveEvent(c, existingEvent, updatedEvent)
+}
+
+// decodeEventDocument turns a patched document back into an eventDocument.
+// Unknown or read-only members and values of the wrong type are reported
+// per field.
+func decodeEventDocument(patched interface{}) (*eventDocument, []fieldError) {
+	object, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, []fieldError{{Field: "", Code: "type", Message: "the event must remain a JSON object"}}
+	}
+
+	var writable map[string]interface{}
+	encoded, _ := json.Marshal(eventDocument{})
+	json.Unmarshal(encoded, &writable)
+
+	var fields []fieldError
+	for name := range object {
+		if _, ok := writable[name]; !ok {
+			fields = append(fields, fieldError{Field: name, Code: "read_only", Message: "is read-only or unknown"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, fields
+	}
+
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, []fieldError{{Field: "", Code: "type", Message: err.Error()}}
+	}
+	var doc eventDocument
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, []fieldError{{Field: typeErr.Field, Code: "type", Message: "must be " + jsonTypeName(typeErr.Type)}}
+		}
+		var timeErr *time.ParseError
+		if errors.As(err, &timeErr) {
+			field := "PublishAt"
+			if s, ok := object["EndsAt"].(string); ok && s == timeErr.Value {
+				field = "EndsAt"
+			}
+			return nil, []fieldError{{Field: field, Code: "datetime", Message: "must be an RFC 3339 timestamp"}}
+		}
+		return nil, []fieldError{{Field: "", Code: "type", Message: err.Error()}}
+	}
+	return &doc, nil
+}
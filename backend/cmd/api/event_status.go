@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type eventStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft published postponed cancelled completed"`
+	Reason string `json:"reason" binding:"max=2000"`
+	// Optional new date, e.g. when a postponed event is rescheduled
+	DateTime string `json:"dateTime"`
+}
+
+func (app *application) changeEventStatus(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil {
+		return
+	}
+
+	var input eventStatusRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	// Calling an event off is as serious as deleting it
+	permission := database.PermissionEditDetails
+	if input.Status == database.EventStatusCancelled {
+		permission = database.PermissionDelete
+	}
+	if !app.requireEventPermission(c, event, permission) {
+		return
+	}
+
+	if !event.CanTransition(input.Status) {
+		problem(c, http.StatusConflict, fmt.Sprintf("A %s event cannot become %s", event.Status, input.Status))
+		return
+	}
+	if input.Reason == "" && (input.Status == database.EventStatusCancelled || input.Status == database.EventStatusPostponed) {
+		problem(c, http.StatusBadRequest, "A reason is required to cancel or postpone an event")
+		return
+	}
+
+	previous := *event
+	err := app.models.Events.SetStatus(event, input.Status, input.Reason, input.DateTime)
+	if err != nil {
+		respondError(c, err, "Failed to update event status")
+		return
+	}
+
+	app.auditEvent(c, database.AuditActionStatusChange, &previous, event)
+	app.notifyEventStatus(&previous, event)
+	c.Header("ETag", eventETag(event))
+	c.JSON(http.StatusOK, event)
+}
+
+// notifyEventStatus tells everyone registered when an event is called off,
+// postponed or brought back with a new date. Failures are logged since the
+// change itself has been saved.
+func (app *application) notifyEventStatus(previous, event *database.Event) {
+	var subject, body string
+	switch {
+	case event.Status == database.EventStatusCancelled:
+		subject = fmt.Sprintf("%s has been cancelled", event.Name)
+		body = fmt.Sprintf("%s, planned for %s at %s, has been cancelled.", event.Name, previous.DateTime, event.Location)
+	case event.Status == database.EventStatusPostponed:
+		subject = fmt.Sprintf("%s has been postponed", event.Name)
+		body = fmt.Sprintf("%s, planned for %s at %s, has been postponed.", event.Name, previous.DateTime, event.Location)
+	case previous.Status == database.EventStatusPostponed && event.Status == database.EventStatusPublished:
+		subject = fmt.Sprintf("%s has a new date", event.Name)
+		body = fmt.Sprintf("%s is back on: %s at %s.", event.Name, event.DateTime, event.Location)
+	default:
+		return
+	}
+	if event.StatusReason != "" {
+		body += "\n\nMessage from the organizer:\n" + event.StatusReason
+	}
+	body += "\n\nYour registration has been kept."
+
+	registrations, err := app.models.Attendees.GetRegistrationsByEvent(event.Id, "")
+	if err != nil {
+		log.Printf("❌ Could not load registrations for event %d status notice: %v\n", event.Id, err)
+		return
+	}
+	for _, r := range registrations {
+		if r.Status == database.AttendeeStatusRejected {
+			continue
+		}
+		if err := app.mailer.Send(r.Email, subject, body); err != nil {
+			log.Printf("❌ Could not send status notice to user %d: %v\n", r.UserId, err)
+		}
+	}
+}
+
+// publishScheduledEvents makes drafts live once their publishAt has passed
+func (app *application) publishScheduledEvents() error {
+	events, err := app.models.Events.PublishDue()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		app.audit(nil, database.AuditEntry{
+			OrganizationId: event.OrganizationId,
+			EventId:        &event.Id,
+			EntityType:     database.AuditEntityEvent,
+			EntityId:       event.Id,
+			Action:         database.AuditActionStatusChange,
+			Changes: map[string]database.FieldChange{
+				"Status": {From: database.EventStatusDraft, To: event.Status},
+			},
+		})
+		log.Printf("📣 Published scheduled event %d (%s)\n", event.Id, event.Name)
+	}
+	return nil
+}
@@ -1,67 +1,225 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 	"rest-api-in-gin/internal/database"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// eventRequest is the body for creating or replacing an event
+type eventRequest struct {
+	Name             string     `json:"name" binding:"required,min=3,max=255"`
+	Description      string     `json:"description" binding:"required,min=10,max=10000"`
+	Location         string     `json:"location" binding:"required_without=VenueId,omitempty,min=3,max=500"`
+	DateTime         *time.Time `json:"dateTime" binding:"required"`
+	Visibility       string     `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	RequiresApproval bool       `json:"requiresApproval"`
+	// Seats including guests; omit for unlimited
+	Capacity  *int `json:"capacity" binding:"omitempty,min=1"`
+	MaxGuests int  `json:"maxGuests" binding:"min=0,max=100"`
+	// Defaults to a full refund
+	RefundPercent     *int       `json:"refundPercent" binding:"omitempty,min=0,max=100"`
+	RefundCutoffHours int        `json:"refundCutoffHours" binding:"min=0"`
+	PublishAt         *time.Time `json:"publishAt" binding:"omitempty,future"`
+	// Only on create; lifecycle changes go through changeEventStatus
+	Status string `json:"status" binding:"omitempty,oneof=draft published"`
+	// Omit both to have them looked up from the address or location
+	Latitude  *float64        `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64        `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	Address   *addressRequest `json:"address"`
+	// A saved venue fills in the location, address and coordinates left out
+	VenueId *int       `json:"venueId" binding:"omitempty,min=1"`
+	RoomId  *int       `json:"roomId" binding:"omitempty,min=1"`
+	EndsAt  *time.Time `json:"endsAt"`
+	// One of the organization's categories; see GET /categories
+	CategoryId *int     `json:"categoryId" binding:"omitempty,min=1"`
+	Tags       []string `json:"tags" binding:"max=10,dive,min=1,max=50"`
+}
+
+type addressRequest struct {
+	Street     string `json:"street" binding:"max=255"`
+	City       string `json:"city" binding:"max=100"`
+	Region     string `json:"region" binding:"max=100"`
+	PostalCode string `json:"postalCode" binding:"max=20"`
+	Country    string `json:"country" binding:"max=100"`
+}
+
+// toAddress trims the parts, returning nil when none are given
+func (r *addressRequest) toAddress() *database.Address {
+	if r == nil {
+		return nil
+	}
+	address := database.Address{
+		Street:     strings.TrimSpace(r.Street),
+		City:       strings.TrimSpace(r.City),
+		Region:     strings.TrimSpace(r.Region),
+		PostalCode: strings.TrimSpace(r.PostalCode),
+		Country:    strings.TrimSpace(r.Country),
+	}
+	if address == (database.Address{}) {
+		return nil
+	}
+	return &address
+}
+
+// applyTo copies the writable fields onto event, filling in defaults
+func (r *eventRequest) applyTo(event *database.Event) {
+	event.Name = strings.TrimSpace(r.Name)
+	event.Description = strings.TrimSpace(r.Description)
+	event.Location = strings.TrimSpace(r.Location)
+	// Stored without a zone, so normalize to UTC
+	event.DateTime = r.DateTime.UTC().Format(time.RFC3339)
+	event.Visibility = r.Visibility
+	if event.Visibility == "" {
+		event.Visibility = database.VisibilityPublic
+	}
+	event.RequiresApproval = r.RequiresApproval
+	event.Capacity = r.Capacity
+	event.MaxGuests = r.MaxGuests
+	event.RefundPercent = 100
+	if r.RefundPercent != nil {
+		event.RefundPercent = *r.RefundPercent
+	}
+	event.RefundCutoffHours = r.RefundCutoffHours
+	event.PublishAt = r.PublishAt
+	event.Latitude = r.Latitude
+	event.Longitude = r.Longitude
+	event.Address = r.Address.toAddress()
+	event.VenueId = r.VenueId
+	event.RoomId = r.RoomId
+	event.EndsAt = nil
+	if r.EndsAt != nil {
+		endsAt := r.EndsAt.UTC()
+		event.EndsAt = &endsAt
+	}
+	event.CategoryId = r.CategoryId
+	event.Tags = normalizeTags(r.Tags)
+}
+
 func (app *application) createEvent(c *gin.Context) {
-	// 1️⃣ Decode JSON into a temporary map
-	var input map[string]interface{}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	// 1️⃣ Decode and validate the request body
+	var input eventRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	// 2️⃣ Extract and normalize fields (handle both camelCase and PascalCase)
-	name := getString(input, "name", getString(input, "Name", ""))
-	description := getString(input, "description", getString(input, "Description", ""))
-	location := getString(input, "location", getString(input, "Location", ""))
-	// Accept dateTime, DateTime, date, or Date
-	dateTime := getString(input, "dateTime", getString(input, "DateTime", getString(input, "date", getString(input, "Date", ""))))
-
-	// 3️⃣ Extract userId from context (set by AuthMiddleware)
+	// 2️⃣ Extract userId from context (set by AuthMiddleware)
 	userId, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Only members can create events in an organization
+	if currentOrgRole(c) == "" {
+		problem(c, http.StatusForbidden, "You are not a member of this organization")
 		return
 	}
 
-	// 4️⃣ Build final event struct
+	// 3️⃣ Build final event struct
 	event := database.Event{
-		Name:        name,
-		Description: description,
-		Location:    location,
-		DateTime:    dateTime,
-		OwnerId:     userId.(int),
+		OrganizationId: currentOrgId(c),
+		OwnerId:        userId.(int),
+		Status:         input.Status,
+	}
+	input.applyTo(&event)
+	// Scheduling implies a draft until the time comes
+	if event.Status == "" && event.PublishAt != nil {
+		event.Status = database.EventStatusDraft
+	} else if event.Status == "" {
+		event.Status = database.EventStatusPublished
 	}
 
-	// 5️⃣ Validate manually
-	if event.Name == "" || event.Description == "" || event.Location == "" || event.DateTime == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields (name, description, location, dateTime) are required"})
+	// 4️⃣ Check what the field rules cannot see on their own
+	if fields := checkEventSchedule(nil, &event); len(fields) > 0 {
+		validationFailed(c, "Invalid event", fields...)
 		return
 	}
 
-	// 6️⃣ Insert into DB
+	if !app.checkCategory(c, &event) || !app.placeAtVenue(c, nil, &event) {
+		return
+	}
+	app.locateEvent(c.Request.Context(), nil, &event)
+
+	// 5️⃣ Insert into DB
 	if err := app.models.Events.Insert(&event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err, "Failed to create event")
 		return
 	}
+	app.auditEvent(c, database.AuditActionCreate, nil, &event)
 
+	c.Header("ETag", eventETag(&event))
 	c.JSON(http.StatusCreated, event)
 }
 
-// Helper to safely read strings from map[string]interface{}
-func getString(m map[string]interface{}, key, fallback string) string {
-	if val, ok := m[key]; ok {
-		if s, ok := val.(string); ok {
-			return s
+// checkEventSchedule applies the rules that depend on the event's current
+// state: a new or moved date must be in the future, the end must follow the
+// start, and only drafts may be scheduled for publication. existing is nil
+// for new events.
+func checkEventSchedule(existing, event *database.Event) []fieldError {
+	var fields []fieldError
+
+	if t, err := time.Parse(time.RFC3339, event.DateTime); err == nil && !t.After(time.Now()) {
+		// Past events can still be edited as long as the date stays put
+		moved := true
+		if existing != nil {
+			if was, err := time.Parse(time.RFC3339, existing.DateTime); err == nil && was.Equal(t) {
+				moved = false
+			}
+		}
+		if moved {
+			fields = append(fields, fieldError{Field: "dateTime", Code: "future", Message: "must be in the future"})
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, event.DateTime); err == nil && event.EndsAt != nil && !event.EndsAt.After(t) {
+		fields = append(fields, fieldError{Field: "endsAt", Code: "after_start", Message: "must be after dateTime"})
+	}
+
+	var previous *time.Time
+	if existing != nil {
+		previous = existing.PublishAt
+	}
+	if event.PublishAt != nil && !sameTime(previous, event.PublishAt) && event.Status != database.EventStatusDraft {
+		fields = append(fields, fieldError{Field: "publishAt", Code: "draft_only", Message: "can only be set on drafts"})
+	}
+	return fields
+}
+
+// parseStatuses reads ?status=published,postponed. It writes a 400 and
+// returns false when a state is unknown.
+func parseStatuses(c *gin.Context) ([]string, bool) {
+	raw := c.Query("status")
+	if raw == "" {
+		return nil, true
+	}
+	statuses := strings.Split(raw, ",")
+	for _, s := range statuses {
+		if !database.ValidEventStatus(s) {
+			problem(c, http.StatusBadRequest, "Unknown event status: "+s)
+			return nil, false
 		}
 	}
-	return fallback
+	return statuses, true
+}
+
+// parseEventFilter reads ?status=, ?category= (a slug) and ?tag=, a comma
+// separated list of tags events must all carry
+func parseEventFilter(c *gin.Context) (database.EventFilter, bool) {
+	var filter database.EventFilter
+	var ok bool
+	if filter.Statuses, ok = parseStatuses(c); !ok {
+		return filter, false
+	}
+	filter.Category = strings.ToLower(strings.TrimSpace(c.Query("category")))
+	if raw := c.Query("tag"); raw != "" {
+		filter.Tags = normalizeTags(strings.Split(raw, ","))
+	}
+	return filter, true
 }
 
 //Get events return all events
@@ -71,17 +229,32 @@ func getString(m map[string]interface{}, key, fallback string) string {
 //@Tags events
 //@Accept json
 //@Produce json
+//@Param near query string false "latitude,longitude to list events around, nearest first"
+//@Param radius query number false "Search radius in km around near (default 25)"
+//@Param category query string false "Slug of the category to list"
+//@Param tag query string false "Comma-separated tags the events must all carry"
 //@Success 200 {object} []database.Event
 //@Router /api/v1/events [get]
 func (app *application) getAllEvents(c *gin.Context) {
-	events, err := app.models.Events.GetAll()
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	if c.Query("near") != "" {
+		app.getEventsNear(c, filter)
+		return
+	}
+
+	// Organization admins see everything; others only what visibility allows
+	var events []*database.Event
+	var err error
+	if database.CanManage(currentOrgRole(c)) {
+		events, err = app.models.Events.GetAll(currentOrgId(c), filter)
+	} else {
+		events, err = app.models.Events.GetAllVisible(currentOrgId(c), viewerId(c), filter)
+	}
 	if err != nil {
-		// Log the actual error for debugging
-		println("❌ Error in getAllEvents:", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve events",
-			"message": err.Error(), // Temporarily expose for debugging
-		})
+		serverError(c, err, "Failed to retrieve events")
 		return
 	}
 	
@@ -90,190 +263,277 @@ func (app *application) getAllEvents(c *gin.Context) {
 		events = []*database.Event{}
 	}
 	
-	println("✅ Successfully loaded", len(events), "events")
 	c.JSON(http.StatusOK, events)
 }
 
 func (app *application) getEvent(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
-		return
-	}
-	event, err := app.models.Events.Get(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
-		return
-	}
-	if event == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	event := app.loadVisibleEvent(c)
+	if event == nil || notModified(c, event) {
 		return
 	}
 	c.JSON(http.StatusOK, event)
 }
 
+// updateEvent replaces the event's writable fields with the request body.
+// Fields left out go back to their defaults; use PATCH to change only some.
 func (app *application) updateEvent(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+	existingEvent := app.loadEvent(c)
+	if existingEvent == nil {
 		return
 	}
-	
-	existingEvent, err := app.models.Events.Get(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
-		return
-	}
-	if existingEvent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	if !app.requireEventPermission(c, existingEvent, database.PermissionEditDetails) || !requireIfMatch(c, existingEvent) {
 		return
 	}
 
-	// Get userId from context to verify ownership
-	userId, _ := c.Get("userId")
-	if existingEvent.OwnerId != userId.(int) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own events"})
+	var input eventRequest
+	if !bindJSON(c, &input) {
 		return
 	}
-
-	// Use map to handle flexible field names
-	var input map[string]interface{}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if input.Status != "" && input.Status != existingEvent.Status {
+		validationFailed(c, "Invalid event", fieldError{
+			Field:   "status",
+			Code:    "read_only",
+			Message: "is changed through POST /events/:id/status",
+		})
 		return
 	}
 
-	// Extract fields
-	name := getString(input, "name", getString(input, "Name", existingEvent.Name))
-	description := getString(input, "description", getString(input, "Description", existingEvent.Description))
-	location := getString(input, "location", getString(input, "Location", existingEvent.Location))
-	dateTime := getString(input, "dateTime", getString(input, "DateTime", getString(input, "date", getString(input, "Date", existingEvent.DateTime))))
+	updatedEvent := editableCopy(existingEvent)
+	input.applyTo(updatedEvent)
+	app.saveEvent(c, existingEvent, updatedEvent)
+}
 
-	updatedEvent := &database.Event{
-		Id:          id,
-		Name:        name,
-		Description: description,
-		Location:    location,
-		DateTime:    dateTime,
-		OwnerId:     existingEvent.OwnerId,
+// editableCopy keeps what clients cannot change through PUT or PATCH and
+// leaves every writable field at its zero value
+func editableCopy(event *database.Event) *database.Event {
+	return &database.Event{
+		Id:             event.Id,
+		OrganizationId: event.OrganizationId,
+		OwnerId:        event.OwnerId,
+
+		// Lifecycle changes go through changeEventStatus
+		Status:          event.Status,
+		StatusReason:    event.StatusReason,
+		StatusChangedAt: event.StatusChangedAt,
+
+		Version: event.Version,
 	}
+}
 
-	if err := app.models.Events.Update(updatedEvent); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+// saveEvent stores a validated replacement for existingEvent, then responds
+// with the result
+func (app *application) saveEvent(c *gin.Context, existingEvent, updatedEvent *database.Event) {
+	if fields := checkEventSchedule(existingEvent, updatedEvent); len(fields) > 0 {
+		validationFailed(c, "Invalid event", fields...)
 		return
 	}
+	if !app.checkCategory(c, updatedEvent) || !app.placeAtVenue(c, existingEvent, updatedEvent) {
+		return
+	}
+	app.locateEvent(c.Request.Context(), existingEvent, updatedEvent)
+
+	err := app.models.Events.Update(updatedEvent)
+	if errors.Is(err, database.ErrEditConflict) {
+		editConflict(c)
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to update event")
+		return
+	}
+	app.auditEvent(c, database.AuditActionUpdate, existingEvent, updatedEvent)
+	c.Header("ETag", eventETag(updatedEvent))
 	c.JSON(http.StatusOK, updatedEvent)
 }
 
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
 func (app *application) deleteEvent(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		problem(c, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
 	// Verify ownership
-	existingEvent, err := app.models.Events.Get(id)
+	existingEvent, err := app.models.Events.Get(currentOrgId(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		serverError(c, err, "Failed to retrieve event")
 		return
 	}
 	if existingEvent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		problem(c, http.StatusNotFound, "Event not found")
+		return
+	}
+
+	if !app.requireEventPermission(c, existingEvent, database.PermissionDelete) || !requireIfMatch(c, existingEvent) {
 		return
 	}
 
-	userId, _ := c.Get("userId")
-	if existingEvent.OwnerId != userId.(int) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own events"})
+	// Deleting hides the registrations without telling anyone; cancelling notifies them
+	headcount, err := app.models.Attendees.GetHeadcount(existingEvent.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check registrations")
+		return
+	}
+	if headcount > 0 {
+		problem(c, http.StatusConflict, "Event has registrations; cancel it instead of deleting")
 		return
 	}
 
-	if err := app.models.Events.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+	err = app.models.Events.Delete(existingEvent.OrganizationId, id, existingEvent.Version, currentUser(c).Id)
+	if errors.Is(err, database.ErrEditConflict) {
+		editConflict(c)
 		return
 	}
+	if err != nil {
+		serverError(c, err, "Failed to delete event")
+		return
+	}
+	app.auditEvent(c, database.AuditActionDelete, existingEvent, nil)
 	c.JSON(http.StatusNoContent, nil)
 }
 
 func (app *application) addAttendeeToEvent(c *gin.Context) {
 	eventId, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		problem(c, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 	userId, err := strconv.Atoi(c.Param("userId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
-	event, err := app.models.Events.Get(eventId)
+	event, err := app.models.Events.Get(currentOrgId(c), eventId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		serverError(c, err, "Failed to retrieve event")
 		return
 	}
 	if event == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		problem(c, http.StatusNotFound, "Event not found")
 		return
 	}
+	// Users may sign themselves up to open events; adding others, or anyone to
+	// a private event, needs attendee management rights
+	if userId != currentUser(c).Id || event.Visibility == database.VisibilityPrivate {
+		if !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+			return
+		}
+	}
 	userToAdd, err := app.models.Users.Get(userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		serverError(c, err, "Failed to retrieve user")
 		return
 	}
 	if userToAdd == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		problem(c, http.StatusNotFound, "User not found")
 		return
 	}
 	existingAttendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, userToAdd.Id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing attendees"})
+		serverError(c, err, "Failed to check existing attendees")
 		return
 	}
 	if existingAttendee != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User is already an attendee of this event"})
+		problem(c, http.StatusConflict, "User is already an attendee of this event")
 		return
 	}
 	attendee := database.Attendee{
 		UserId:  userToAdd.Id,
 		EventId: event.Id,
+		Status:  database.AttendeeStatusConfirmed,
+	}
+	// Paid events are joined through checkout, not directly
+	if userId == currentUser(c).Id {
+		paid, err := app.models.TicketTypes.HasPaidTickets(event.Id)
+		if err != nil {
+			serverError(c, err, "Failed to check ticket types")
+			return
+		}
+		if paid {
+			problem(c, http.StatusPaymentRequired, "Tickets for this event must be purchased through checkout")
+			return
+		}
 	}
-	_, err = app.models.Attendees.Insert(&attendee)
+	// Self sign-ups wait for approval when the event asks for it
+	if event.RequiresApproval && userId == currentUser(c).Id {
+		permissions, err := app.eventPermissions(c, event)
+		if err != nil {
+			serverError(c, err, "Failed to check permissions")
+			return
+		}
+		if !hasPermission(permissions, database.PermissionManageAttendees) {
+			attendee.Status = database.AttendeeStatusPending
+		}
+	}
+	answers, guests, ok := app.readRegistration(c, event, userId == currentUser(c).Id)
+	if !ok {
+		return
+	}
+	_, err = app.models.Attendees.Register(&attendee, answers, guests)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add attendee"})
+		respondError(c, err, "Failed to add attendee")
 		return
 	}
+	app.auditAttendee(c, database.AuditActionCreate, event, attendee.UserId, database.Diff(nil, &attendee))
 	c.JSON(http.StatusCreated, attendee)
 }
 
 func (app *application) getAttendeesForEvent(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+	event := app.loadVisibleEvent(c)
+	if event == nil {
 		return
 	}
-	users, err := app.models.Attendees.GetAttendeesByEvent(id)
+	users, headcount, err := app.models.Attendees.GetAttendeesByEvent(event.Id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendees"})
+		serverError(c, err, "Failed to retrieve attendees")
 		return
 	}
+	// The body stays a plain list; the headcount including guests rides along in a header
+	c.Header("X-Headcount", strconv.Itoa(headcount))
 	c.JSON(http.StatusOK, users)
 }
 
 func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		problem(c, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 	userId, err := strconv.Atoi(c.Param("userId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	event, err := app.models.Events.Get(currentOrgId(c), id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve event")
+		return
+	}
+	if event == nil {
+		problem(c, http.StatusNotFound, "Event not found")
 		return
 	}
-	err = app.models.Attendees.Delete(userId, id)
+	action := database.TicketActionCancelled
+	if userId != currentUser(c).Id {
+		if !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+			return
+		}
+		action = database.TicketActionRemoved
+	}
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove attendee from event"})
+		serverError(c, err, "Failed to retrieve attendee")
+		return
+	}
+	// Removing someone who is not registered is a no-op
+	if attendee != nil && app.cancelAttendee(c, event, attendee, action, "") == nil {
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -282,12 +542,12 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 func (app *application) getEventsByAttendee(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
-	events, err := app.models.Attendees.GetEventsByAttendee(id)
+	events, err := app.models.Attendees.GetEventsByAttendeeInOrganization(currentOrgId(c), id, viewerId(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events for attendee"})
+		serverError(c, err, "Failed to retrieve events for attendee")
 		return
 	}
 	c.JSON(http.StatusOK, events)
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/geocode"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNearRadiusKm = 25
+	maxNearRadiusKm     = 500
+)
+
+// geocodeQuery is the text looked up for an event or venue: its address
+// when it has one, otherwise the free-text location or name
+func geocodeQuery(address *database.Address, location string) string {
+	if a := address; a != nil {
+		var parts []string
+		for _, part := range []string{a.City, a.Region, a.PostalCode, a.Country} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, ", ")
+		}
+	}
+	return location
+}
+
+// locateEvent fills in coordinates the client left out by geocoding the
+// event's address or location. Coordinates carried over unchanged while the
+// venue moved are looked up again. existing is nil for new events. An event
+// that cannot be placed is saved without coordinates.
+func (app *application) locateEvent(ctx context.Context, existing, event *database.Event) {
+	if event.Latitude != nil && event.Longitude != nil {
+		if existing == nil || existing.Latitude == nil || existing.Longitude == nil {
+			return
+		}
+		moved := existing.Location != event.Location || !reflect.DeepEqual(existing.Address, event.Address)
+		kept := *existing.Latitude == *event.Latitude && *existing.Longitude == *event.Longitude
+		if !moved || !kept {
+			return
+		}
+	}
+
+	event.Latitude, event.Longitude = nil, nil
+	place, err := app.geocoder.Geocode(ctx, geocodeQuery(event.Address, event.Location))
+	if err != nil {
+		if !errors.Is(err, geocode.ErrNotFound) {
+			log.Printf("❌ Could not geocode event %d: %v\n", event.Id, err)
+		}
+		return
+	}
+	event.Latitude, event.Longitude = &place.Latitude, &place.Longitude
+}
+
+// isFinite rejects the NaN and Inf that ParseFloat accepts; NaN would slip
+// through every range check
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// parseNear reads ?near=lat,lng and ?radius= (in km). It writes a 400 and
+// returns false when either is malformed.
+func parseNear(c *gin.Context, near *database.NearbySearch) bool {
+	parts := strings.Split(c.Query("near"), ",")
+	if len(parts) != 2 {
+		problem(c, http.StatusBadRequest, "near must be latitude,longitude")
+		return false
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errLat != nil || errLng != nil || !isFinite(lat) || !isFinite(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		problem(c, http.StatusBadRequest, "near must be latitude,longitude in degrees")
+		return false
+	}
+	near.Latitude, near.Longitude = lat, lng
+
+	near.RadiusKm = defaultNearRadiusKm
+	if v := c.Query("radius"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || !isFinite(radius) || radius <= 0 || radius > maxNearRadiusKm {
+			problem(c, http.StatusBadRequest, "radius must be between 0 and "+strconv.Itoa(maxNearRadiusKm)+" km")
+			return false
+		}
+		near.RadiusKm = radius
+	}
+	return true
+}
+
+// getEventsNear lists the filtered events around ?near=, nearest first
+func (app *application) getEventsNear(c *gin.Context, filter database.EventFilter) {
+	near := database.NearbySearch{
+		EventFilter: filter,
+		All:         database.CanManage(currentOrgRole(c)),
+	}
+	if !parseNear(c, &near) {
+		return
+	}
+
+	events, err := app.models.Events.GetNear(currentOrgId(c), viewerId(c), near)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve events")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
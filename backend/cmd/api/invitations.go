@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createInvitationRequest struct {
+	Email          string `json:"email" binding:"omitempty,email"`
+	MaxUses        *int   `json:"maxUses" binding:"omitempty,min=1"`
+	ExpiresInHours *int   `json:"expiresInHours" binding:"omitempty,min=1,max=8760"`
+}
+
+type invitationResponse struct {
+	*database.Invitation
+	Token string `json:"Token"`
+	Url   string `json:"Url"`
+}
+
+// viewerId is the caller's user ID, or 0 for anonymous requests
+func viewerId(c *gin.Context) int {
+	if user := currentUser(c); user != nil {
+		return user.Id
+	}
+	return 0
+}
+
+// canViewEvent applies the event's visibility. Public and unlisted events are
+// open to anyone with the link; private ones only to the owner, staff,
+// organization admins and confirmed attendees. Drafts are previews for the
+// first three.
+func (app *application) canViewEvent(c *gin.Context, event *database.Event) (bool, error) {
+	draft := event.Status == database.EventStatusDraft
+	if event.Visibility != database.VisibilityPrivate && !draft {
+		return true, nil
+	}
+	user := currentUser(c)
+	if user == nil {
+		return false, nil
+	}
+	permissions, err := app.eventPermissions(c, event)
+	if err != nil {
+		return false, err
+	}
+	if len(permissions) > 0 {
+		return true, nil
+	}
+	if draft {
+		return false, nil
+	}
+	// Pending and rejected requests do not open up a private event
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, user.Id)
+	if err != nil {
+		return false, err
+	}
+	return attendee != nil && attendee.Status == database.AttendeeStatusConfirmed, nil
+}
+
+// loadVisibleEvent is loadEvent plus a visibility check. Hidden events are
+// reported as not found so their existence is not revealed.
+func (app *application) loadVisibleEvent(c *gin.Context) *database.Event {
+	event := app.loadEvent(c)
+	if event == nil {
+		return nil
+	}
+	ok, err := app.canViewEvent(c, event)
+	if err != nil {
+		serverError(c, err, "Failed to check event visibility")
+		return nil
+	}
+	if !ok {
+		problem(c, http.StatusNotFound, "Event not found")
+		return nil
+	}
+	return event
+}
+
+func (app *application) invitationUrl(token string) string {
+	return fmt.Sprintf("%s/invitations/%s", strings.TrimRight(app.frontendUrl, "/"), token)
+}
+
+func (app *application) createInvitation(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+
+	var input createInvitationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	token, tokenHash, err := generateToken()
+	if err != nil {
+		serverError(c, err, "Failed to generate invitation token")
+		return
+	}
+
+	invitation := database.Invitation{
+		EventId:   event.Id,
+		Email:     strings.TrimSpace(input.Email),
+		MaxUses:   input.MaxUses,
+		CreatedBy: currentUser(c).Id,
+		TokenHash: tokenHash,
+	}
+	// Invitations sent to an address are always single-use
+	if invitation.Email != "" {
+		one := 1
+		invitation.MaxUses = &one
+	}
+	if input.ExpiresInHours != nil {
+		expiresAt := time.Now().Add(time.Duration(*input.ExpiresInHours) * time.Hour)
+		invitation.ExpiresAt = &expiresAt
+	}
+
+	if err := app.models.Invitations.Insert(&invitation); err != nil {
+		respondError(c, err, "Failed to create invitation")
+		return
+	}
+
+	url := app.invitationUrl(token)
+	if invitation.Email != "" {
+		body := fmt.Sprintf("You have been invited to %s on %s at %s.\n\nAccept the invitation here:\n%s",
+			event.Name, event.DateTime, event.Location, url)
+		if err := app.mailer.Send(invitation.Email, "You're invited: "+event.Name, body); err != nil {
+			serverError(c, err, "Failed to send invitation email")
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, invitationResponse{Invitation: &invitation, Token: token, Url: url})
+}
+
+func (app *application) getInvitations(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	invitations, err := app.models.Invitations.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve invitations")
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (app *application) revokeInvitation(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	invitationId, err := strconv.Atoi(c.Param("invitationId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+	if err := app.models.Invitations.Revoke(event.Id, invitationId); err != nil {
+		serverError(c, err, "Failed to revoke invitation")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// loadInvitation resolves :token to an invitation and its event. Invitations
+// work across organizations, so the event is read without the tenant filter.
+func (app *application) loadInvitation(c *gin.Context) (*database.Invitation, *database.Event) {
+	invitation, err := app.models.Invitations.GetByTokenHash(hashToken(c.Param("token")))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve invitation")
+		return nil, nil
+	}
+	if invitation == nil {
+		problem(c, http.StatusNotFound, "Invitation not found")
+		return nil, nil
+	}
+	event, err := app.models.Events.GetById(invitation.EventId)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve event")
+		return nil, nil
+	}
+	if event == nil {
+		problem(c, http.StatusNotFound, "Event not found")
+		return nil, nil
+	}
+	return invitation, event
+}
+
+func (app *application) getInvitation(c *gin.Context) {
+	invitation, event := app.loadInvitation(c)
+	if invitation == nil {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"Event":     event,
+		"Usable":    invitation.Usable(),
+		"ExpiresAt": invitation.ExpiresAt,
+	})
+}
+
+func (app *application) acceptInvitation(c *gin.Context) {
+	invitation, event := app.loadInvitation(c)
+	if invitation == nil {
+		return
+	}
+
+	user := currentUser(c)
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, user.Email) {
+		problem(c, http.StatusForbidden, "This invitation was sent to a different email address")
+		return
+	}
+
+	existing, err := app.models.Attendees.GetByEventAndAttendee(event.Id, user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check existing attendees")
+		return
+	}
+	if existing != nil {
+		problem(c, http.StatusConflict, "User is already an attendee of this event")
+		return
+	}
+	// An invitation lets the guest in, it does not waive the ticket price
+	paid, err := app.models.TicketTypes.HasPaidTickets(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check ticket types")
+		return
+	}
+	if paid {
+		problem(c, http.StatusPaymentRequired, "Tickets for this event must be purchased through checkout")
+		return
+	}
+
+	answers, guests, ok := app.readRegistration(c, event, true)
+	if !ok {
+		return
+	}
+
+	attendee, err := app.models.Invitations.Redeem(invitation.Id, user.Id, answers, guests)
+	if err != nil {
+		respondError(c, err, "Failed to accept invitation")
+		return
+	}
+	app.auditAttendee(c, database.AuditActionCreate, event, attendee.UserId, database.Diff(nil, attendee))
+	c.JSON(http.StatusCreated, attendee)
+}
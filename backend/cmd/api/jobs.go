@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// runPeriodically calls job every interval until the process exits.
+// Errors are logged and the job is retried on the next tick.
+func runPeriodically(name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := job(); err != nil {
+				log.Printf("❌ Job %s failed: %v\n", name, err)
+			}
+		}
+	}()
+}
+
+// startBackgroundJobs registers every recurring job the API depends on
+func (app *application) startBackgroundJobs() {
+	runPeriodically("account-deletion", time.Hour, app.processAccountDeletions)
+	runPeriodically("order-expiry", time.Minute, app.expireOrders)
+	runPeriodically("scheduled-publishing", time.Minute, app.publishScheduledEvents)
+	runPeriodically("trash-purge", time.Hour, app.purgeDeleted)
+}
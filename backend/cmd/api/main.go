@@ -10,6 +10,9 @@ import (
 
 	"rest-api-in-gin/internal/database"
 	"rest-api-in-gin/internal/env"
+	"rest-api-in-gin/internal/geocode"
+	"rest-api-in-gin/internal/mailer"
+	"rest-api-in-gin/internal/payments"
 
 	_ "github.com/joho/godotenv/autoload"
 	_ "github.com/lib/pq" // PostgreSQL driver
@@ -26,6 +29,23 @@ type application struct {
 	port      int
 	jwtSecret string
 	models    database.Models
+	mailer    mailer.Mailer
+
+	frontendUrl  string
+	ticketSecret string
+
+	// Email of the account that owns the shared default organization
+	defaultOrgOwner string
+
+	deletionGracePeriod time.Duration
+	trashRetention      time.Duration
+
+	payments     payments.Provider
+	orderTimeout time.Duration
+	// Enables the fake gateway and payment simulation; never in production
+	devMode bool
+
+	geocoder geocode.Geocoder
 }
 
 func main() {
@@ -44,19 +64,101 @@ func main() {
 
 	models := database.NewModels(db)
 
+	geocoder, err := geocode.NewOfflineGeocoder()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	port := env.GetEnvInt("PORT", 8080)
+	devMode := isDevEnvironment(env.GetEnvString("APP_ENV", "production"))
+	paymentProvider, err := newPaymentProvider(devMode, fmt.Sprintf("http://localhost:%d/api/v1/payments/webhook", port))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	app := &application{
-		port:      env.GetEnvInt("PORT", 8080),
+		port:      port,
 		jwtSecret: "supersecretkey123",
 		models:    models,
+		mailer:    mailer.LogMailer{},
+
+		frontendUrl:  env.GetEnvString("FRONTEND_URL", "http://localhost:3000"),
+		ticketSecret: env.GetEnvString("TICKET_SECRET", "supersecretticketkey123"),
+
+		defaultOrgOwner: normalizeEmail(os.Getenv("DEFAULT_ORG_OWNER_EMAIL")),
+
+		deletionGracePeriod: time.Duration(env.GetEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour,
+		trashRetention:      time.Duration(env.GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+
+		payments:     paymentProvider,
+		orderTimeout: time.Duration(env.GetEnvInt("ORDER_TIMEOUT_MINUTES", 15)) * time.Minute,
+		devMode:      devMode,
+
+		geocoder: geocoder,
 	}
 
 	log.Println("✅ Connected to PostgreSQL successfully!")
 
+	if err := app.assignDefaultOrgOwner(); err != nil {
+		log.Fatal(err)
+	}
+
+	app.startBackgroundJobs()
+
 	if err := app.serve(); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// assignDefaultOrgOwner gives the account named by DEFAULT_ORG_OWNER_EMAIL
+// ownership of the default organization. Nobody is promoted without it; an
+// account that registers later is made owner at sign-up.
+func (app *application) assignDefaultOrgOwner() error {
+	if app.defaultOrgOwner == "" {
+		return nil
+	}
+	assigned, err := app.models.Organizations.AssignDefaultOwner(app.defaultOrgOwner)
+	if err != nil {
+		return fmt.Errorf("assigning default organization owner: %w", err)
+	}
+	if !assigned {
+		log.Printf("⚠️ %s has no account yet; it will own the default organization once it registers\n", app.defaultOrgOwner)
+	}
+	return nil
+}
+
+// isDevEnvironment reports whether APP_ENV allows development-only features
+func isDevEnvironment(appEnv string) bool {
+	return appEnv == "development" || appEnv == "test"
+}
+
+// newPaymentProvider picks the gateway from PAYMENT_PROVIDER. There is no
+// default: stripe needs its keys, and the fake gateway, which lets buyers
+// settle their own orders, only runs in development and tests.
+func newPaymentProvider(devMode bool, webhookURL string) (payments.Provider, error) {
+	switch provider := os.Getenv("PAYMENT_PROVIDER"); provider {
+	case "stripe":
+		secretKey, webhookSecret := os.Getenv("STRIPE_SECRET_KEY"), os.Getenv("STRIPE_WEBHOOK_SECRET")
+		if secretKey == "" || webhookSecret == "" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER=stripe needs STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET")
+		}
+		return payments.NewStripeProvider(secretKey, webhookSecret), nil
+	case "fake":
+		if !devMode {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER=fake needs APP_ENV=development or test")
+		}
+		return payments.NewFakeProvider(
+			env.GetEnvString("FAKE_PAYMENT_OUTCOME", payments.FakeOutcomeManual),
+			os.Getenv("FAKE_PAYMENT_SECRET"),
+			webhookURL,
+		)
+	case "":
+		return nil, fmt.Errorf("PAYMENT_PROVIDER must be set to stripe or fake")
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q", provider)
+	}
+}
+
 func (app *application) serve() error {
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", app.port),
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/payments"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type orderItemRequest struct {
+	TicketTypeId int `json:"ticketTypeId" binding:"required"`
+	Quantity     int `json:"quantity" binding:"required,min=1,max=50"`
+}
+
+type checkoutRequest struct {
+	registrationRequest
+	Items     []orderItemRequest `json:"items" binding:"required,min=1,max=20,dive"`
+	PromoCode string             `json:"promoCode" binding:"max=50"`
+}
+
+type simulatePaymentRequest struct {
+	Outcome string `json:"outcome" binding:"required,oneof=succeed fail"`
+}
+
+type checkoutResponse struct {
+	Order    *database.Order    `json:"Order"`
+	Payment  *payments.Payment  `json:"Payment,omitempty"`
+	Attendee *database.Attendee `json:"Attendee,omitempty"`
+}
+
+func (app *application) createOrder(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	user := currentUser(c)
+
+	var input checkoutRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	existing, err := app.models.Attendees.GetByEventAndAttendee(event.Id, user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check existing attendees")
+		return
+	}
+	if existing != nil {
+		problem(c, http.StatusConflict, "You are already registered for this event")
+		return
+	}
+	pending, err := app.models.Orders.GetPendingForUser(event.Id, user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check open orders")
+		return
+	}
+	if pending != nil {
+		problem(c, http.StatusConflict, "You already have an open order for this event", gin.H{"Order": pending})
+		return
+	}
+
+	answers, guests, ok := app.validateRegistration(c, event, input.registrationRequest, true)
+	if !ok {
+		return
+	}
+
+	order := database.Order{
+		EventId:         event.Id,
+		UserId:          user.Id,
+		PaymentProvider: app.payments.Name(),
+		ExpiresAt:       time.Now().Add(app.orderTimeout),
+		PromoCode:       input.PromoCode,
+		Registration:    database.OrderRegistration{Answers: answers, Guests: guests},
+	}
+	// Repeated ticket types are merged so availability is checked once for the lot
+	positions := make(map[int]int)
+	for _, item := range input.Items {
+		if i, ok := positions[item.TicketTypeId]; ok {
+			order.Items[i].Quantity += item.Quantity
+			continue
+		}
+		positions[item.TicketTypeId] = len(order.Items)
+		order.Items = append(order.Items, database.OrderItem{TicketTypeId: item.TicketTypeId, Quantity: item.Quantity})
+	}
+	// One ticket for the buyer and one per named guest
+	if order.Quantity() != 1+len(guests) {
+		validationFailed(c, "Ticket quantity must cover you and each guest", fieldError{
+			Field:   "items",
+			Code:    "quantity",
+			Message: fmt.Sprintf("expected %d tickets in total", 1+len(guests)),
+		})
+		return
+	}
+
+	err = app.models.Orders.Create(&order)
+	var ticketErr *database.TicketError
+	var promoErr *database.PromoCodeError
+	switch {
+	case errors.As(err, &ticketErr):
+		problem(c, http.StatusConflict, "Ticket type "+strconv.Itoa(ticketErr.TicketTypeId)+" "+ticketErr.Reason)
+		return
+	case errors.As(err, &promoErr):
+		validationFailed(c, "Promo code "+promoErr.Code+" "+promoErr.Reason, fieldError{
+			Field:   "promoCode",
+			Code:    "unusable",
+			Message: promoErr.Reason,
+		})
+		return
+	case err != nil:
+		respondError(c, err, "Failed to create order")
+		return
+	}
+
+	// Free tickets skip the gateway entirely
+	if order.TotalCents == 0 {
+		attendee, err := app.models.Orders.MarkPaid(order.Id)
+		if err != nil {
+			respondError(c, err, "Failed to complete order")
+			return
+		}
+		order.Status = database.OrderStatusPaid
+		c.JSON(http.StatusCreated, checkoutResponse{Order: &order, Attendee: attendee})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+	payment, err := app.payments.CreatePayment(ctx, payments.PaymentRequest{
+		OrderId:       order.Id,
+		AmountCents:   order.TotalCents,
+		Currency:      order.Currency,
+		Description:   fmt.Sprintf("%s — order #%d", event.Name, order.Id),
+		CustomerEmail: user.Email,
+	})
+	if err != nil {
+		log.Printf("❌ Payment provider rejected order %d: %v\n", order.Id, err)
+		if _, err := app.models.Orders.Close(order.Id, database.OrderStatusFailed); err != nil {
+			log.Printf("❌ Could not release order %d: %v\n", order.Id, err)
+		}
+		problem(c, http.StatusBadGateway, "Payment provider is unavailable, please try again")
+		return
+	}
+	if err := app.models.Orders.SetPaymentReference(order.Id, payment.Reference); err != nil {
+		respondError(c, err, "Failed to save payment reference")
+		return
+	}
+	order.PaymentReference = payment.Reference
+
+	c.JSON(http.StatusCreated, checkoutResponse{Order: &order, Payment: payment})
+}
+
+// loadOwnOrder parses :orderId and makes sure it belongs to the caller
+func (app *application) loadOwnOrder(c *gin.Context) *database.Order {
+	id, err := strconv.Atoi(c.Param("orderId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid order ID")
+		return nil
+	}
+	order, err := app.models.Orders.Get(id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve order")
+		return nil
+	}
+	if order == nil || order.UserId != currentUser(c).Id {
+		problem(c, http.StatusNotFound, "Order not found")
+		return nil
+	}
+	return order
+}
+
+func (app *application) getOrder(c *gin.Context) {
+	order := app.loadOwnOrder(c)
+	if order == nil {
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (app *application) getMyOrders(c *gin.Context) {
+	orders, err := app.models.Orders.GetByUser(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve orders")
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func (app *application) getEventOrders(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	orders, err := app.models.Orders.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve orders")
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+func (app *application) cancelOrder(c *gin.Context) {
+	order := app.loadOwnOrder(c)
+	if order == nil {
+		return
+	}
+	if order.Status != database.OrderStatusPending {
+		problem(c, http.StatusConflict, "Only pending orders can be cancelled")
+		return
+	}
+	if order.PaymentReference != "" {
+		if err := app.payments.CancelPayment(c.Request.Context(), order.PaymentReference); err != nil {
+			problem(c, http.StatusBadGateway, "Payment provider could not cancel the payment")
+			return
+		}
+	}
+	if _, err := app.models.Orders.Close(order.Id, database.OrderStatusCancelled); err != nil {
+		serverError(c, err, "Failed to cancel order")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) paymentWebhook(c *gin.Context) {
+	event, err := app.payments.ParseWebhook(c.Request)
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid webhook")
+		return
+	}
+	if err := app.applyPaymentEvent(event); err != nil {
+		log.Printf("❌ Failed to apply payment webhook for %s: %v\n", event.Reference, err)
+		// A 5xx makes the provider retry later
+		serverError(c, err, "Failed to process webhook")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// applyPaymentEvent moves the order matching a provider event forward.
+// Unknown references and event types are ignored.
+func (app *application) applyPaymentEvent(event *payments.WebhookEvent) error {
+	if event.Type == "" {
+		return nil
+	}
+	order, err := app.models.Orders.GetByPaymentReference(app.payments.Name(), event.Reference)
+	if err != nil || order == nil {
+		return err
+	}
+
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		attendee, err := app.models.Orders.MarkPaid(order.Id)
+		if errors.Is(err, database.ErrAlreadyRegistered) {
+			// The buyer registered some other way while paying
+			return app.refundUnfulfilledOrder(order, database.OrderStatusCancelled)
+		}
+		if err != nil {
+			return err
+		}
+		if attendee == nil {
+			// Already paid, or paid after the reservation lapsed
+			return app.refundUnfulfilledOrder(order, database.OrderStatusExpired)
+		}
+		app.auditPaidRegistration(attendee)
+	case payments.EventPaymentFailed:
+		if _, err := app.models.Orders.Close(order.Id, database.OrderStatusFailed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refundUnfulfilledOrder closes an order that a successful payment can no
+// longer complete with the given status and gives the money back. Orders
+// that did get paid are left alone.
+func (app *application) refundUnfulfilledOrder(order *database.Order, status string) error {
+	// Closing first keeps a concurrent webhook from paying it after all
+	if _, err := app.models.Orders.Close(order.Id, status); err != nil {
+		return err
+	}
+	current, err := app.models.Orders.Get(order.Id)
+	if err != nil || current == nil {
+		return err
+	}
+	if current.Status == database.OrderStatusPaid || current.Status == database.OrderStatusRefunded {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	reference, err := app.payments.Refund(ctx, payments.RefundRequest{
+		Reference:   current.PaymentReference,
+		AmountCents: current.TotalCents,
+		// Webhook retries must not refund twice
+		IdempotencyKey: fmt.Sprintf("refund-order-%d", current.Id),
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("⚠️ Refunded payment %s for %s order %d (refund %s)\n", current.PaymentReference, current.Status, current.Id, reference)
+	return app.models.Orders.MarkRefunded(current.Id, current.TotalCents)
+}
+
+// simulateFakePayment runs a fake webhook through the same parsing and
+// handling as a real one
+func (app *application) simulateFakePayment(fake *payments.FakeProvider, reference, outcome string) error {
+	req, err := fake.Simulate("/api/v1/payments/webhook", reference, outcome)
+	if err != nil {
+		return err
+	}
+	event, err := fake.ParseWebhook(req)
+	if err != nil {
+		return err
+	}
+	return app.applyPaymentEvent(event)
+}
+
+func (app *application) simulatePayment(c *gin.Context) {
+	fake, ok := app.payments.(*payments.FakeProvider)
+	if !ok {
+		problem(c, http.StatusNotFound, "Payment simulation is only available with the fake provider")
+		return
+	}
+	order := app.loadOwnOrder(c)
+	if order == nil {
+		return
+	}
+	var input simulatePaymentRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if order.PaymentReference == "" {
+		problem(c, http.StatusConflict, "Order has no payment to settle")
+		return
+	}
+
+	if err := app.simulateFakePayment(fake, order.PaymentReference, input.Outcome); err != nil {
+		serverError(c, err, "Failed to simulate payment")
+		return
+	}
+	updated, err := app.models.Orders.Get(order.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve order")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// expireOrders releases inventory held by checkouts that were never paid
+func (app *application) expireOrders() error {
+	orders, err := app.models.Orders.GetExpired()
+	if err != nil {
+		return err
+	}
+	var failures []error
+	for _, order := range orders {
+		if order.PaymentReference != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			if err := app.payments.CancelPayment(ctx, order.PaymentReference); err != nil {
+				log.Printf("⚠️ Could not cancel payment for expired order %d: %v\n", order.Id, err)
+			}
+			cancel()
+		}
+		if _, err := app.models.Orders.Close(order.Id, database.OrderStatusExpired); err != nil {
+			failures = append(failures, fmt.Errorf("order %d: %w", order.Id, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// auditPaidRegistration records a registration created by a payment. The
+// webhook has no user behind it, so the entry carries no actor.
+func (app *application) auditPaidRegistration(attendee *database.Attendee) {
+	event, err := app.models.Events.GetById(attendee.EventId)
+	if err != nil || event == nil {
+		log.Printf("❌ Could not load event %d to audit paid registration: %v\n", attendee.EventId, err)
+		return
+	}
+	app.auditAttendee(nil, database.AuditActionCreate, event, attendee.UserId, database.Diff(nil, attendee))
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=255"`
+	Slug string `json:"slug" binding:"required,min=2,max=100,alphanum"`
+}
+
+type addMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+type updateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// loadMembership parses :orgId and returns the organization along with the
+// caller's role. It writes the error response itself and returns nil when the
+// organization does not exist or the caller is not a member.
+func (app *application) loadMembership(c *gin.Context) (*database.Organization, string) {
+	orgId, err := strconv.Atoi(c.Param("orgId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid organization ID")
+		return nil, ""
+	}
+	org, err := app.models.Organizations.Get(orgId)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve organization")
+		return nil, ""
+	}
+	role := ""
+	if org != nil {
+		role, err = app.models.Organizations.GetRole(org.Id, currentUser(c).Id)
+		if err != nil {
+			serverError(c, err, "Failed to check organization membership")
+			return nil, ""
+		}
+	}
+	if org == nil || role == "" {
+		problem(c, http.StatusNotFound, "Organization not found")
+		return nil, ""
+	}
+	org.Role = role
+	return org, role
+}
+
+func (app *application) createOrganization(c *gin.Context) {
+	var input createOrganizationRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	org := database.Organization{Name: input.Name, Slug: input.Slug}
+	if err := app.models.Organizations.Insert(&org, currentUser(c).Id); err != nil {
+		respondError(c, err, "Failed to create organization")
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+func (app *application) getMyOrganizations(c *gin.Context) {
+	orgs, err := app.models.Organizations.GetForUser(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve organizations")
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+func (app *application) getOrganization(c *gin.Context) {
+	org, _ := app.loadMembership(c)
+	if org == nil {
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// getOrganizationMembers lists the organization's members. Everyone
+// registered belongs to the default organization, so emails are kept to
+// admins.
+func (app *application) getOrganizationMembers(c *gin.Context) {
+	org, role := app.loadMembership(c)
+	if org == nil {
+		return
+	}
+	members, err := app.models.Organizations.GetMembers(org.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve members")
+		return
+	}
+	if !database.CanManage(role) {
+		for _, member := range members {
+			member.Email = ""
+		}
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (app *application) addOrganizationMember(c *gin.Context) {
+	org, role := app.loadMembership(c)
+	if org == nil {
+		return
+	}
+	if !database.CanManage(role) {
+		problem(c, http.StatusForbidden, "Only organization admins can add members")
+		return
+	}
+
+	var input addMemberRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if input.Role == database.OrgRoleOwner && role != database.OrgRoleOwner {
+		problem(c, http.StatusForbidden, "Only owners can grant the owner role")
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(normalizeEmail(input.Email))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve user")
+		return
+	}
+	if user == nil {
+		problem(c, http.StatusNotFound, "User not found")
+		return
+	}
+	existing, err := app.models.Organizations.GetRole(org.Id, user.Id)
+	if err != nil {
+		serverError(c, err, "Failed to check organization membership")
+		return
+	}
+	if existing != "" {
+		problem(c, http.StatusConflict, "User is already a member of this organization")
+		return
+	}
+
+	if err := app.models.Organizations.AddMember(org.Id, user.Id, input.Role); err != nil {
+		respondError(c, err, "Failed to add member")
+		return
+	}
+	c.JSON(http.StatusCreated, database.OrganizationMember{
+		OrganizationId: org.Id,
+		UserId:         user.Id,
+		Name:           user.Name,
+		Email:          user.Email,
+		Role:           input.Role,
+	})
+}
+
+func (app *application) updateOrganizationMember(c *gin.Context) {
+	org, role := app.loadMembership(c)
+	if org == nil {
+		return
+	}
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input updateMemberRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	targetRole, err := app.models.Organizations.GetRole(org.Id, userId)
+	if err != nil {
+		serverError(c, err, "Failed to check organization membership")
+		return
+	}
+	if targetRole == "" {
+		problem(c, http.StatusNotFound, "Member not found")
+		return
+	}
+
+	// Admins manage members; only owners may touch the owner role
+	touchesOwner := targetRole == database.OrgRoleOwner || input.Role == database.OrgRoleOwner
+	if !database.CanManage(role) || (touchesOwner && role != database.OrgRoleOwner) {
+		problem(c, http.StatusForbidden, "You cannot change this member's role")
+		return
+	}
+	if targetRole == database.OrgRoleOwner && input.Role != database.OrgRoleOwner {
+		if ok := app.ensureAnotherOwner(c, org.Id); !ok {
+			return
+		}
+	}
+
+	if err := app.models.Organizations.SetRole(org.Id, userId, input.Role); err != nil {
+		respondError(c, err, "Failed to update member")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"OrganizationId": org.Id, "UserId": userId, "Role": input.Role})
+}
+
+func (app *application) removeOrganizationMember(c *gin.Context) {
+	org, role := app.loadMembership(c)
+	if org == nil {
+		return
+	}
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	targetRole, err := app.models.Organizations.GetRole(org.Id, userId)
+	if err != nil {
+		serverError(c, err, "Failed to check organization membership")
+		return
+	}
+	if targetRole == "" {
+		problem(c, http.StatusNotFound, "Member not found")
+		return
+	}
+
+	// Members may always leave; removing others needs admin rights
+	leaving := userId == currentUser(c).Id
+	if !leaving && (!database.CanManage(role) || (targetRole == database.OrgRoleOwner && role != database.OrgRoleOwner)) {
+		problem(c, http.StatusForbidden, "You cannot remove this member")
+		return
+	}
+	if targetRole == database.OrgRoleOwner {
+		if ok := app.ensureAnotherOwner(c, org.Id); !ok {
+			return
+		}
+	}
+
+	if err := app.models.Organizations.RemoveMember(org.Id, userId); err != nil {
+		serverError(c, err, "Failed to remove member")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ensureAnotherOwner stops an organization from losing its last owner
+func (app *application) ensureAnotherOwner(c *gin.Context, orgId int) bool {
+	owners, err := app.models.Organizations.CountOwners(orgId)
+	if err != nil {
+		serverError(c, err, "Failed to count owners")
+		return false
+	}
+	if owners <= 1 {
+		problem(c, http.StatusConflict, "An organization must keep at least one owner")
+		return false
+	}
+	return true
+}
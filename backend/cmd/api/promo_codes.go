@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type promoCodeRequest struct {
+	Code          string     `json:"code" binding:"required,min=3,max=50,alphanum"`
+	DiscountType  string     `json:"discountType" binding:"required,oneof=percent fixed"`
+	DiscountValue int64      `json:"discountValue" binding:"required,min=1"`
+	TicketTypeIds []int      `json:"ticketTypeIds"`
+	MaxUses       *int       `json:"maxUses" binding:"omitempty,min=1"`
+	StartsAt      *time.Time `json:"startsAt"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	Active        *bool      `json:"active"`
+}
+
+// applyPromoCodeRequest validates the request against the event's ticket types and copies it
+// onto p, returning a message for the client when something is off
+func (app *application) applyPromoCodeRequest(r *promoCodeRequest, event *database.Event, p *database.PromoCode) (string, error) {
+	if r.DiscountType == database.DiscountPercent && r.DiscountValue > 100 {
+		return "A percentage discount cannot exceed 100", nil
+	}
+	if r.StartsAt != nil && r.ExpiresAt != nil && !r.StartsAt.Before(*r.ExpiresAt) {
+		return "startsAt must be before expiresAt", nil
+	}
+
+	if len(r.TicketTypeIds) > 0 {
+		types, err := app.models.TicketTypes.GetByEvent(event.Id)
+		if err != nil {
+			return "", err
+		}
+		known := make(map[int]bool, len(types))
+		for _, t := range types {
+			known[t.Id] = true
+		}
+		for _, id := range r.TicketTypeIds {
+			if !known[id] {
+				return "Ticket type " + strconv.Itoa(id) + " does not belong to this event", nil
+			}
+		}
+	}
+
+	p.Code = r.Code
+	p.DiscountType = r.DiscountType
+	p.DiscountValue = r.DiscountValue
+	p.TicketTypeIds = r.TicketTypeIds
+	if p.TicketTypeIds == nil {
+		p.TicketTypeIds = []int{}
+	}
+	p.MaxUses = r.MaxUses
+	p.StartsAt = r.StartsAt
+	p.ExpiresAt = r.ExpiresAt
+	if r.Active != nil {
+		p.Active = *r.Active
+	}
+	return "", nil
+}
+
+func (app *application) loadPromoCode(c *gin.Context, event *database.Event) *database.PromoCode {
+	id, err := strconv.Atoi(c.Param("promoCodeId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid promo code ID")
+		return nil
+	}
+	promoCode, err := app.models.PromoCodes.Get(event.Id, id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve promo code")
+		return nil
+	}
+	if promoCode == nil {
+		problem(c, http.StatusNotFound, "Promo code not found")
+		return nil
+	}
+	return promoCode
+}
+
+func (app *application) getPromoCodes(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	codes, err := app.models.PromoCodes.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve promo codes")
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+func (app *application) createPromoCode(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+
+	var input promoCodeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	promoCode := database.PromoCode{EventId: event.Id, Active: true}
+	msg, err := app.applyPromoCodeRequest(&input, event, &promoCode)
+	if err != nil {
+		serverError(c, err, "Failed to check ticket types")
+		return
+	}
+	if msg != "" {
+		problem(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	err = app.models.PromoCodes.Insert(&promoCode)
+	if errors.Is(err, database.ErrDuplicate) {
+		problem(c, http.StatusConflict, "This event already has a promo code with that name")
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to create promo code")
+		return
+	}
+	c.JSON(http.StatusCreated, promoCode)
+}
+
+func (app *application) updatePromoCode(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	promoCode := app.loadPromoCode(c, event)
+	if promoCode == nil {
+		return
+	}
+
+	var input promoCodeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	// Orders already placed keep the discount they were given
+	if promoCode.Uses > 0 && (input.DiscountType != promoCode.DiscountType || input.DiscountValue != promoCode.DiscountValue) {
+		problem(c, http.StatusConflict, "The discount of a redeemed code cannot change; create a new code instead")
+		return
+	}
+	msg, err := app.applyPromoCodeRequest(&input, event, promoCode)
+	if err != nil {
+		serverError(c, err, "Failed to check ticket types")
+		return
+	}
+	if msg != "" {
+		problem(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	err = app.models.PromoCodes.Update(promoCode)
+	if errors.Is(err, database.ErrDuplicate) {
+		problem(c, http.StatusConflict, "This event already has a promo code with that name")
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to update promo code")
+		return
+	}
+	c.JSON(http.StatusOK, promoCode)
+}
+
+func (app *application) deletePromoCode(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	promoCode := app.loadPromoCode(c, event)
+	if promoCode == nil {
+		return
+	}
+
+	err := app.models.PromoCodes.Delete(event.Id, promoCode.Id)
+	if err != nil {
+		respondError(c, err, "Failed to delete promo code")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) getPromoCodeReport(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	report, err := app.models.PromoCodes.GetReport(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to build promo code report")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (app *application) getPromoCodeRedemptions(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	promoCode := app.loadPromoCode(c, event)
+	if promoCode == nil {
+		return
+	}
+	orders, err := app.models.Orders.GetByPromoCode(promoCode.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve redemptions")
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
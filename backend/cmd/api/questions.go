@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type questionInput struct {
+	Id       int      `json:"id"`
+	Label    string   `json:"label" binding:"required,max=500"`
+	Type     string   `json:"type" binding:"required"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type replaceQuestionsRequest struct {
+	Questions []questionInput `json:"questions" binding:"max=50,dive"`
+}
+
+type guestInput struct {
+	Name  string `json:"name" binding:"required,min=1,max=255"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type registrationRequest struct {
+	Answers database.Answers `json:"answers"`
+	Guests  []guestInput     `json:"guests" binding:"dive"`
+}
+
+// attendeeExport is one row of the attendee export
+type attendeeExport struct {
+	*database.Registration
+	Answers database.Answers `json:"Answers"`
+	Guests  []database.Guest `json:"Guests"`
+}
+
+func (app *application) getEventQuestions(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	questions, err := app.models.Questions.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve questions")
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+func (app *application) replaceEventQuestions(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+
+	var input replaceQuestionsRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	questions := make([]*database.Question, 0, len(input.Questions))
+	var problems []fieldError
+	for i, q := range input.Questions {
+		question := &database.Question{
+			Id:       q.Id,
+			Label:    strings.TrimSpace(q.Label),
+			Type:     q.Type,
+			Options:  q.Options,
+			Required: q.Required,
+		}
+		if question.Options == nil {
+			question.Options = []string{}
+		}
+		if err := question.CheckDefinition(); err != nil {
+			problems = append(problems, fieldError{Field: fmt.Sprintf("questions[%d]", i), Code: "definition", Message: err.Error()})
+		}
+		questions = append(questions, question)
+	}
+	if len(problems) > 0 {
+		validationFailed(c, "Invalid questions", problems...)
+		return
+	}
+
+	if err := app.models.Questions.Replace(event.Id, questions); err != nil {
+		respondError(c, err, "Failed to save questions")
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// readRegistration decodes the optional registration body, validates its
+// answers against the event's questions and its guests against the plus-one
+// cap. It writes the error response itself and returns false when the request
+// should stop.
+func (app *application) readRegistration(c *gin.Context, event *database.Event, enforceRequired bool) (database.Answers, []database.Guest, bool) {
+	var input registrationRequest
+	// The body is optional; an empty one registers without answers or guests
+	if !bindJSON(c, &input) {
+		return nil, nil, false
+	}
+	return app.validateRegistration(c, event, input, enforceRequired)
+}
+
+// validateRegistration is readRegistration for a body that is already decoded
+func (app *application) validateRegistration(c *gin.Context, event *database.Event, input registrationRequest, enforceRequired bool) (database.Answers, []database.Guest, bool) {
+	if len(input.Guests) > event.MaxGuests {
+		validationFailed(c, "Too many guests", fieldError{
+			Field:   "guests",
+			Code:    "max",
+			Message: fmt.Sprintf("at most %d guests are allowed", event.MaxGuests),
+		})
+		return nil, nil, false
+	}
+	guests := make([]database.Guest, 0, len(input.Guests))
+	for _, g := range input.Guests {
+		guests = append(guests, database.Guest{Name: strings.TrimSpace(g.Name), Email: g.Email})
+	}
+
+	questions, err := app.models.Questions.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve questions")
+		return nil, nil, false
+	}
+
+	if problems := database.ValidateAnswers(questions, input.Answers, enforceRequired); len(problems) > 0 {
+		fields := make([]fieldError, 0, len(problems))
+		for id, msg := range problems {
+			fields = append(fields, fieldError{Field: fmt.Sprintf("answers.%d", id), Code: "answer", Message: msg})
+		}
+		validationFailed(c, "Invalid answers", fields...)
+		return nil, nil, false
+	}
+	return input.Answers, guests, true
+}
+
+func (app *application) exportAttendees(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+
+	registrations, err := app.models.Attendees.GetRegistrationsByEvent(event.Id, c.Query("status"))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registrations")
+		return
+	}
+	questions, err := app.models.Questions.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve questions")
+		return
+	}
+	answers, err := app.models.Questions.GetAnswersByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve answers")
+		return
+	}
+	guests, err := app.models.Attendees.GetGuestsByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve guests")
+		return
+	}
+
+	filename := fmt.Sprintf("event-%d-attendees", event.Id)
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		rows := make([]attendeeExport, 0, len(registrations))
+		for _, r := range registrations {
+			a := answers[r.AttendeeId]
+			if a == nil {
+				a = database.Answers{}
+			}
+			g := guests[r.AttendeeId]
+			if g == nil {
+				g = []database.Guest{}
+			}
+			rows = append(rows, attendeeExport{Registration: r, Answers: a, Guests: g})
+		}
+		c.JSON(http.StatusOK, gin.H{"Questions": questions, "Attendees": rows})
+	case "csv":
+		data, err := attendeesCSV(registrations, questions, answers, guests)
+		if err != nil {
+			serverError(c, err, "Failed to build export")
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	default:
+		problem(c, http.StatusBadRequest, "format must be json or csv")
+	}
+}
+
+// attendeesCSV writes one row per registration with a column per question
+func attendeesCSV(registrations []*database.Registration, questions []*database.Question, answers map[int]database.Answers, guests map[int][]database.Guest) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"Name", "Email", "Status", "RegisteredAt", "Guests"}
+	for _, q := range questions {
+		header = append(header, q.Label)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, r := range registrations {
+		guestNames := make([]string, 0, len(guests[r.AttendeeId]))
+		for _, g := range guests[r.AttendeeId] {
+			guestNames = append(guestNames, g.Name)
+		}
+		row := []string{r.Name, r.Email, r.Status, r.CreatedAt.Format("2006-01-02 15:04:05"), strings.Join(guestNames, "; ")}
+		for _, q := range questions {
+			row = append(row, answerText(answers[r.AttendeeId][q.Id]))
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// answerText flattens a JSON answer into a spreadsheet-friendly string
+func answerText(value json.RawMessage) string {
+	if len(value) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(value, &list) == nil {
+		return strings.Join(list, "; ")
+	}
+	var n float64
+	if json.Unmarshal(value, &n) == nil {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return string(value)
+}
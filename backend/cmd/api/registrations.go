@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+type decideRegistrationsRequest struct {
+	UserIds  []int  `json:"userIds" binding:"required,min=1,max=500"`
+	Decision string `json:"decision" binding:"required,oneof=approve reject"`
+	Message  string `json:"message" binding:"max=2000"`
+}
+
+func (app *application) getEventRegistrations(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", database.AttendeeStatusPending, database.AttendeeStatusConfirmed, database.AttendeeStatusRejected:
+	default:
+		problem(c, http.StatusBadRequest, "status must be pending, confirmed or rejected")
+		return
+	}
+
+	registrations, err := app.models.Attendees.GetRegistrationsByEvent(event.Id, status)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registrations")
+		return
+	}
+	c.JSON(http.StatusOK, registrations)
+}
+
+func (app *application) decideRegistrations(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+
+	var input decideRegistrationsRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	status := database.AttendeeStatusConfirmed
+	if input.Decision == "reject" {
+		status = database.AttendeeStatusRejected
+	}
+
+	changed, err := app.models.Attendees.Decide(event.Id, input.UserIds, status, input.Message, currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to update registrations")
+		return
+	}
+
+	for _, userId := range changed {
+		app.auditAttendee(c, database.AuditActionUpdate, event, userId, map[string]database.FieldChange{
+			"Status": {From: database.AttendeeStatusPending, To: status},
+		})
+	}
+	app.notifyRegistrationDecision(event, changed, status, input.Message)
+
+	c.JSON(http.StatusOK, gin.H{"Status": status, "UserIds": changed})
+}
+
+// notifyRegistrationDecision emails each affected user. Failures are logged
+// rather than returned since the decision itself has been saved.
+func (app *application) notifyRegistrationDecision(event *database.Event, userIds []int, status, message string) {
+	subject := fmt.Sprintf("Your registration for %s was approved", event.Name)
+	if status == database.AttendeeStatusRejected {
+		subject = fmt.Sprintf("Your registration for %s was declined", event.Name)
+	}
+	body := subject + "."
+	if message != "" {
+		body += "\n\nMessage from the organizer:\n" + message
+	}
+
+	for _, id := range userIds {
+		user, err := app.models.Users.Get(id)
+		if err != nil || user == nil {
+			log.Printf("❌ Could not load user %d for registration notice: %v\n", id, err)
+			continue
+		}
+		if err := app.mailer.Send(user.Email, subject, body); err != nil {
+			log.Printf("❌ Could not send registration notice to user %d: %v\n", id, err)
+		}
+	}
+}
+
+func (app *application) getMyRegistration(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registration")
+		return
+	}
+	if attendee == nil {
+		problem(c, http.StatusNotFound, "You are not registered for this event")
+		return
+	}
+	c.JSON(http.StatusOK, attendee)
+}
+
+func (app *application) getMyRegistrations(c *gin.Context) {
+	registrations, err := app.models.Attendees.GetRegistrationsByUser(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve registrations")
+		return
+	}
+	c.JSON(http.StatusOK, registrations)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
@@ -11,36 +12,159 @@ import (
 )
 
 func (app *application) routes() http.Handler {
-	g := gin.Default()
+	g := gin.New()
+	g.Use(gin.Logger(), gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		serverError(c, fmt.Errorf("panic: %v", recovered), "Something went wrong")
+		c.Abort()
+	}))
+	g.HandleMethodNotAllowed = true
+	g.NoRoute(func(c *gin.Context) {
+		problem(c, http.StatusNotFound, "No such endpoint")
+	})
+	g.NoMethod(func(c *gin.Context) {
+		problem(c, http.StatusMethodNotAllowed, c.Request.Method+" is not allowed here")
+	})
 
 	// ✅ Enable CORS for frontend on localhost:3000
 	g.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Organization-Id", "X-Request-Id", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "X-Headcount", "X-Request-Id", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	g.Use(app.RequestIdMiddleware())
 
 	v1 := g.Group("/api/v1")
 	{
-		v1.GET("/events", app.getAllEvents)
-		v1.GET("/events/:id", app.getEvent)
-		v1.GET("/events/:id/attendees", app.getAttendeesForEvent)
-		v1.GET("/attendees/:id/events", app.getEventsByAttendee)
 		v1.POST("/auth/register", app.registerUser)
 		v1.POST("/auth/login", app.login)
+		v1.GET("/users/:id", app.getUserProfile)
+	}
+
+	// Event reads are public but still scoped to one organization
+	publicTenant := v1.Group("/")
+	publicTenant.Use(app.OptionalAuthMiddleware(), app.TenantMiddleware())
+	{
+		publicTenant.GET("/events", app.getAllEvents)
+		publicTenant.GET("/events/search", app.searchEvents)
+		publicTenant.GET("/events/:id", app.getEvent)
+		publicTenant.GET("/events/:id/attendees", app.getAttendeesForEvent)
+		publicTenant.GET("/events/:id/questions", app.getEventQuestions)
+		publicTenant.GET("/events/:id/ticket-types", app.getTicketTypes)
+		publicTenant.GET("/attendees/:id/events", app.getEventsByAttendee)
+		publicTenant.GET("/categories", app.getCategories)
+		publicTenant.GET("/tags", app.suggestTags)
 	}
 
+	// Payment providers authenticate with their own signatures
+	v1.POST("/payments/webhook", app.paymentWebhook)
+
+	// Invitation tokens carry their own access and span organizations
+	v1.GET("/invitations/:token", app.OptionalAuthMiddleware(), app.getInvitation)
+
 	authGroup := v1.Group("/")
 	authGroup.Use(app.AuthMiddleware())
 	{
-		authGroup.POST("/events", app.createEvent)
-		authGroup.PUT("/events/:id", app.updateEvent)
-		authGroup.DELETE("/events/:id", app.deleteEvent)
-		authGroup.POST("/events/:id/attendees/:userId", app.addAttendeeToEvent)
-		authGroup.DELETE("/events/:id/attendees/:userId", app.deleteAttendeeFromEvent)
+		authGroup.GET("/me", app.getCurrentUser)
+		authGroup.PATCH("/me", app.updateCurrentUser)
+		authGroup.POST("/me/password", app.changePassword)
+		authGroup.POST("/me/email/verify", app.verifyEmail)
+		authGroup.DELETE("/me", app.deleteCurrentUser)
+		authGroup.DELETE("/me/deletion", app.cancelAccountDeletion)
+		authGroup.GET("/me/export", app.exportCurrentUser)
+		authGroup.GET("/me/registrations", app.getMyRegistrations)
+
+		authGroup.POST("/organizations", app.createOrganization)
+		authGroup.GET("/organizations", app.getMyOrganizations)
+		authGroup.GET("/organizations/:orgId", app.getOrganization)
+		authGroup.GET("/organizations/:orgId/members", app.getOrganizationMembers)
+		authGroup.POST("/organizations/:orgId/members", app.addOrganizationMember)
+		authGroup.PATCH("/organizations/:orgId/members/:userId", app.updateOrganizationMember)
+		authGroup.DELETE("/organizations/:orgId/members/:userId", app.removeOrganizationMember)
+
+		authGroup.POST("/invitations/:token/accept", app.acceptInvitation)
+
+		authGroup.GET("/me/orders", app.getMyOrders)
+		authGroup.GET("/me/ticket-history", app.getMyTicketHistory)
+		authGroup.GET("/orders/:orderId", app.getOrder)
+		authGroup.POST("/orders/:orderId/cancel", app.cancelOrder)
+		if app.devMode {
+			authGroup.POST("/orders/:orderId/simulate", app.simulatePayment)
+		}
+	}
+
+	tenantGroup := authGroup.Group("/")
+	tenantGroup.Use(app.TenantMiddleware())
+	{
+		tenantGroup.POST("/events", app.createEvent)
+		tenantGroup.PUT("/events/:id", app.updateEvent)
+		tenantGroup.PATCH("/events/:id", app.patchEvent)
+		tenantGroup.DELETE("/events/:id", app.deleteEvent)
+		tenantGroup.POST("/events/:id/restore", app.restoreEvent)
+		tenantGroup.GET("/trash/events", app.getDeletedEvents)
+		tenantGroup.GET("/trash/users", app.getDeletedUsers)
+		tenantGroup.POST("/trash/users/:userId/restore", app.restoreUser)
+		tenantGroup.GET("/events/:id/trash/attendees", app.getDeletedAttendees)
+		tenantGroup.POST("/events/:id/trash/attendees/:attendeeId/restore", app.restoreAttendee)
+		tenantGroup.GET("/events/:id/history", app.getEventHistory)
+		tenantGroup.GET("/audit-log", app.getAuditLog)
+		tenantGroup.POST("/events/:id/status", app.changeEventStatus)
+		tenantGroup.POST("/events/:id/attendees/:userId", app.addAttendeeToEvent)
+		tenantGroup.DELETE("/events/:id/attendees/:userId", app.deleteAttendeeFromEvent)
+
+		tenantGroup.GET("/events/:id/permissions", app.getMyEventPermissions)
+		tenantGroup.GET("/events/:id/staff", app.getEventStaff)
+		tenantGroup.PUT("/events/:id/staff/:userId", app.setEventStaff)
+		tenantGroup.DELETE("/events/:id/staff/:userId", app.removeEventStaff)
+		tenantGroup.POST("/events/:id/transfer", app.transferEventOwnership)
+
+		tenantGroup.GET("/events/:id/invitations", app.getInvitations)
+		tenantGroup.POST("/events/:id/invitations", app.createInvitation)
+		tenantGroup.DELETE("/events/:id/invitations/:invitationId", app.revokeInvitation)
+
+		tenantGroup.GET("/events/:id/registration", app.getMyRegistration)
+		tenantGroup.POST("/events/:id/registration/cancel", app.cancelMyRegistration)
+		tenantGroup.POST("/events/:id/registration/transfer", app.transferMyTicket)
+		tenantGroup.GET("/events/:id/ticket-history", app.getEventTicketHistory)
+		tenantGroup.GET("/events/:id/registrations", app.getEventRegistrations)
+		tenantGroup.POST("/events/:id/registrations/decisions", app.decideRegistrations)
+
+		tenantGroup.PUT("/events/:id/questions", app.replaceEventQuestions)
+		tenantGroup.GET("/events/:id/attendees/export", app.exportAttendees)
+
+		tenantGroup.GET("/events/:id/ticket", app.getMyTicket)
+		tenantGroup.POST("/events/:id/checkin", app.checkInAttendee)
+		tenantGroup.GET("/events/:id/checkin/stats", app.getCheckInStats)
+		tenantGroup.GET("/events/:id/checkin/stream", app.streamCheckInStats)
+
+		tenantGroup.POST("/events/:id/ticket-types", app.createTicketType)
+		tenantGroup.PUT("/events/:id/ticket-types/:ticketTypeId", app.updateTicketType)
+		tenantGroup.DELETE("/events/:id/ticket-types/:ticketTypeId", app.deleteTicketType)
+		tenantGroup.POST("/events/:id/orders", app.createOrder)
+		tenantGroup.GET("/events/:id/orders", app.getEventOrders)
+
+		tenantGroup.GET("/events/:id/promo-codes", app.getPromoCodes)
+		tenantGroup.POST("/events/:id/promo-codes", app.createPromoCode)
+		tenantGroup.GET("/events/:id/promo-codes/report", app.getPromoCodeReport)
+		tenantGroup.PUT("/events/:id/promo-codes/:promoCodeId", app.updatePromoCode)
+		tenantGroup.DELETE("/events/:id/promo-codes/:promoCodeId", app.deletePromoCode)
+		tenantGroup.GET("/events/:id/promo-codes/:promoCodeId/redemptions", app.getPromoCodeRedemptions)
+
+		tenantGroup.GET("/venues", app.getVenues)
+		tenantGroup.POST("/venues", app.createVenue)
+		tenantGroup.GET("/venues/:venueId", app.getVenue)
+		tenantGroup.PUT("/venues/:venueId", app.updateVenue)
+		tenantGroup.DELETE("/venues/:venueId", app.deleteVenue)
+		tenantGroup.POST("/venues/:venueId/rooms", app.createRoom)
+		tenantGroup.PUT("/venues/:venueId/rooms/:roomId", app.updateRoom)
+		tenantGroup.DELETE("/venues/:venueId/rooms/:roomId", app.deleteRoom)
+		tenantGroup.GET("/venues/:venueId/bookings", app.getVenueBookings)
+
+		tenantGroup.POST("/categories", app.createCategory)
+		tenantGroup.PUT("/categories/:categoryId", app.updateCategory)
+		tenantGroup.DELETE("/categories/:categoryId", app.deleteCategory)
 	}
 
 	g.GET("/swagger/*any", func(c *gin.Context) {
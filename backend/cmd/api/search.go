@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 200
+)
+
+// parseSearchDate accepts an RFC 3339 timestamp or a plain date. A plain
+// date used as an upper bound covers the whole day.
+func parseSearchDate(v string, end bool) (*time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, true
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, false
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+// searchEvents ranks the organization's events against ?q=, optionally
+// narrowed by ?from=, ?to=, ?location= and ?status=
+func (app *application) searchEvents(c *gin.Context) {
+	search := database.EventSearch{
+		Query:    strings.TrimSpace(c.Query("q")),
+		Location: strings.TrimSpace(c.Query("location")),
+		All:      database.CanManage(currentOrgRole(c)),
+		Limit:    defaultSearchLimit,
+	}
+	if database.PrefixQuery(search.Query) == "" {
+		problem(c, http.StatusBadRequest, "q must contain at least one word")
+		return
+	}
+	if len(search.Query) > maxSearchLength {
+		problem(c, http.StatusBadRequest, "q must be at most "+strconv.Itoa(maxSearchLength)+" characters")
+		return
+	}
+
+	for name, target := range map[string]**time.Time{"from": &search.From, "to": &search.Until} {
+		if v := c.Query(name); v != "" {
+			t, ok := parseSearchDate(v, name == "to")
+			if !ok {
+				problem(c, http.StatusBadRequest, name+" must be a date or an RFC 3339 timestamp")
+				return
+			}
+			*target = t
+		}
+	}
+	if search.From != nil && search.Until != nil && !search.Until.After(*search.From) {
+		problem(c, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	if raw := c.Query("status"); raw != "" {
+		search.Statuses = strings.Split(raw, ",")
+		for _, s := range search.Statuses {
+			if !database.ValidEventStatus(s) {
+				problem(c, http.StatusBadRequest, "Unknown event status: "+s)
+				return
+			}
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			problem(c, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxSearchLimit))
+			return
+		}
+		search.Limit = limit
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			problem(c, http.StatusBadRequest, "Invalid offset")
+			return
+		}
+		search.Offset = offset
+	}
+
+	results, err := app.models.Events.Search(currentOrgId(c), viewerId(c), search)
+	if err != nil {
+		serverError(c, err, "Failed to search events")
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type setStaffRequest struct {
+	Role string `json:"role" binding:"required,oneof=co_organizer attendee_manager checkin"`
+}
+
+type transferOwnershipRequest struct {
+	UserId int `json:"userId" binding:"required"`
+}
+
+// eventPermissions lists what the caller may do on the event. The owner and
+// organization admins hold every permission; staff get those of their role.
+func (app *application) eventPermissions(c *gin.Context, event *database.Event) ([]database.Permission, error) {
+	user := currentUser(c)
+	if user == nil {
+		return nil, nil
+	}
+	if user.Id == event.OwnerId || database.CanManage(currentOrgRole(c)) {
+		return database.AllPermissions, nil
+	}
+	role, err := app.models.EventStaff.GetRole(event.Id, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	return database.StaffRolePermissions[role], nil
+}
+
+// requireEventPermission writes a 403 (or 500) and returns false when the
+// caller lacks the permission on the event
+func (app *application) requireEventPermission(c *gin.Context, event *database.Event, permission database.Permission) bool {
+	permissions, err := app.eventPermissions(c, event)
+	if err != nil {
+		serverError(c, err, "Failed to check permissions")
+		return false
+	}
+	if hasPermission(permissions, permission) {
+		return true
+	}
+	problem(c, http.StatusForbidden, "You do not have permission to "+humanizePermission(permission))
+	return false
+}
+
+func hasPermission(permissions []database.Permission, permission database.Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func humanizePermission(permission database.Permission) string {
+	switch permission {
+	case database.PermissionEditDetails:
+		return "edit this event"
+	case database.PermissionManageAttendees:
+		return "manage attendees of this event"
+	case database.PermissionCheckIn:
+		return "check in attendees of this event"
+	case database.PermissionManageStaff:
+		return "manage staff of this event"
+	case database.PermissionDelete:
+		return "delete this event"
+	}
+	return string(permission)
+}
+
+// loadEvent parses :id and fetches the event in the current organization.
+// It writes the error response itself and returns nil on failure.
+func (app *application) loadEvent(c *gin.Context) *database.Event {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid event ID")
+		return nil
+	}
+	event, err := app.models.Events.Get(currentOrgId(c), id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve event")
+		return nil
+	}
+	if event == nil {
+		problem(c, http.StatusNotFound, "Event not found")
+		return nil
+	}
+	return event
+}
+
+func (app *application) getMyEventPermissions(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil {
+		return
+	}
+	permissions, err := app.eventPermissions(c, event)
+	if err != nil {
+		serverError(c, err, "Failed to check permissions")
+		return
+	}
+	if permissions == nil {
+		permissions = []database.Permission{}
+	}
+	c.JSON(http.StatusOK, gin.H{"EventId": event.Id, "Permissions": permissions})
+}
+
+// getEventStaff lists the event's team. Door staff see who they work with
+// but not their email addresses.
+func (app *application) getEventStaff(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil {
+		return
+	}
+	permissions, err := app.eventPermissions(c, event)
+	if err != nil {
+		serverError(c, err, "Failed to check permissions")
+		return
+	}
+	if !hasPermission(permissions, database.PermissionCheckIn) {
+		problem(c, http.StatusForbidden, "You do not have permission to "+humanizePermission(database.PermissionCheckIn))
+		return
+	}
+
+	staff, err := app.models.EventStaff.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve staff")
+		return
+	}
+	if !hasPermission(permissions, database.PermissionManageAttendees) && !hasPermission(permissions, database.PermissionEditDetails) {
+		for _, member := range staff {
+			member.Email = ""
+		}
+	}
+	c.JSON(http.StatusOK, staff)
+}
+
+func (app *application) setEventStaff(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageStaff) {
+		return
+	}
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input setStaffRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if userId == event.OwnerId {
+		problem(c, http.StatusConflict, "The owner cannot also be staff")
+		return
+	}
+
+	// Staff must belong to the event's organization
+	role, err := app.models.Organizations.GetRole(event.OrganizationId, userId)
+	if err != nil {
+		serverError(c, err, "Failed to check organization membership")
+		return
+	}
+	if role == "" {
+		problem(c, http.StatusUnprocessableEntity, "User is not a member of this organization")
+		return
+	}
+
+	if err := app.models.EventStaff.Set(event.Id, userId, input.Role); err != nil {
+		respondError(c, err, "Failed to assign staff")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"EventId": event.Id, "UserId": userId, "Role": input.Role})
+}
+
+func (app *application) removeEventStaff(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil {
+		return
+	}
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	// Staff may step down themselves
+	if userId != currentUser(c).Id && !app.requireEventPermission(c, event, database.PermissionManageStaff) {
+		return
+	}
+
+	if err := app.models.EventStaff.Remove(event.Id, userId); err != nil {
+		serverError(c, err, "Failed to remove staff")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) transferEventOwnership(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil {
+		return
+	}
+	if currentUser(c).Id != event.OwnerId && !database.CanManage(currentOrgRole(c)) {
+		problem(c, http.StatusForbidden, "Only the owner can transfer this event")
+		return
+	}
+	if !requireIfMatch(c, event) {
+		return
+	}
+
+	var input transferOwnershipRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	if input.UserId == event.OwnerId {
+		problem(c, http.StatusConflict, "User already owns this event")
+		return
+	}
+
+	role, err := app.models.Organizations.GetRole(event.OrganizationId, input.UserId)
+	if err != nil {
+		serverError(c, err, "Failed to check organization membership")
+		return
+	}
+	if role == "" {
+		problem(c, http.StatusUnprocessableEntity, "User is not a member of this organization")
+		return
+	}
+
+	previous := *event
+	err = app.models.EventStaff.TransferOwnership(event, input.UserId)
+	if errors.Is(err, database.ErrEditConflict) {
+		editConflict(c)
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to transfer ownership")
+		return
+	}
+	app.auditEvent(c, database.AuditActionOwnershipTransfer, &previous, event)
+	c.Header("ETag", eventETag(event))
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ticketTypeRequest struct {
+	Name        string     `json:"name" binding:"required,max=255"`
+	Description string     `json:"description" binding:"max=2000"`
+	PriceCents  int64      `json:"priceCents" binding:"min=0"`
+	Currency    string     `json:"currency" binding:"required,len=3,alpha"`
+	Quantity    int        `json:"quantity" binding:"min=0"`
+	SalesStart  *time.Time `json:"salesStart"`
+	SalesEnd    *time.Time `json:"salesEnd"`
+}
+
+func (r *ticketTypeRequest) apply(t *database.TicketType) string {
+	if r.SalesStart != nil && r.SalesEnd != nil && !r.SalesStart.Before(*r.SalesEnd) {
+		return "salesStart must be before salesEnd"
+	}
+	t.Name = strings.TrimSpace(r.Name)
+	t.Description = r.Description
+	t.PriceCents = r.PriceCents
+	t.Currency = strings.ToUpper(r.Currency)
+	t.Quantity = r.Quantity
+	t.SalesStart = r.SalesStart
+	t.SalesEnd = r.SalesEnd
+	return ""
+}
+
+// loadTicketType parses :ticketTypeId within an already loaded event
+func (app *application) loadTicketType(c *gin.Context, event *database.Event) *database.TicketType {
+	id, err := strconv.Atoi(c.Param("ticketTypeId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid ticket type ID")
+		return nil
+	}
+	ticketType, err := app.models.TicketTypes.Get(event.Id, id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve ticket type")
+		return nil
+	}
+	if ticketType == nil {
+		problem(c, http.StatusNotFound, "Ticket type not found")
+		return nil
+	}
+	return ticketType
+}
+
+func (app *application) getTicketTypes(c *gin.Context) {
+	event := app.loadVisibleEvent(c)
+	if event == nil {
+		return
+	}
+	types, err := app.models.TicketTypes.GetByEvent(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve ticket types")
+		return
+	}
+	c.JSON(http.StatusOK, types)
+}
+
+func (app *application) createTicketType(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+
+	var input ticketTypeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	ticketType := database.TicketType{EventId: event.Id}
+	if msg := input.apply(&ticketType); msg != "" {
+		problem(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := app.models.TicketTypes.Insert(&ticketType); err != nil {
+		respondError(c, err, "Failed to create ticket type")
+		return
+	}
+	c.JSON(http.StatusCreated, ticketType)
+}
+
+func (app *application) updateTicketType(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	ticketType := app.loadTicketType(c, event)
+	if ticketType == nil {
+		return
+	}
+
+	var input ticketTypeRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	sold := ticketType.Sold + ticketType.Reserved
+	if sold > 0 && (input.PriceCents != ticketType.PriceCents || !strings.EqualFold(input.Currency, ticketType.Currency)) {
+		problem(c, http.StatusConflict, "Price cannot change once tickets have been ordered")
+		return
+	}
+	if input.Quantity < sold {
+		problem(c, http.StatusConflict, "Quantity cannot go below tickets already ordered")
+		return
+	}
+	if msg := input.apply(ticketType); msg != "" {
+		problem(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := app.models.TicketTypes.Update(ticketType); err != nil {
+		respondError(c, err, "Failed to update ticket type")
+		return
+	}
+	c.JSON(http.StatusOK, ticketType)
+}
+
+func (app *application) deleteTicketType(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionEditDetails) {
+		return
+	}
+	ticketType := app.loadTicketType(c, event)
+	if ticketType == nil {
+		return
+	}
+
+	err := app.models.TicketTypes.Delete(event.Id, ticketType.Id)
+	if err != nil {
+		respondError(c, err, "Failed to delete ticket type")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getDeletedEvents lists the organization's trash. Admins see every deleted
+// event; everyone else only the ones they own.
+func (app *application) getDeletedEvents(c *gin.Context) {
+	var ownerId *int
+	if !database.CanManage(currentOrgRole(c)) {
+		ownerId = &currentUser(c).Id
+	}
+
+	events, err := app.models.Events.GetDeleted(currentOrgId(c), ownerId)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve deleted events")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+func (app *application) restoreEvent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	event, err := app.models.Events.GetDeletedById(currentOrgId(c), id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve event")
+		return
+	}
+	if event == nil {
+		problem(c, http.StatusNotFound, "Event not found in trash")
+		return
+	}
+
+	if !app.requireEventPermission(c, event, database.PermissionDelete) {
+		return
+	}
+
+	if err := app.models.Events.Restore(event.OrganizationId, event.Id); err != nil {
+		respondError(c, err, "Failed to restore event")
+		return
+	}
+
+	restored, err := app.models.Events.Get(event.OrganizationId, event.Id)
+	if err != nil || restored == nil {
+		serverError(c, err, "Failed to retrieve restored event")
+		return
+	}
+	app.auditEvent(c, database.AuditActionRestore, event, restored)
+	c.Header("ETag", eventETag(restored))
+	c.JSON(http.StatusOK, restored)
+}
+
+// getDeletedUsers lists the accounts of the organization's members that are
+// in the trash. Only admins can see it.
+func (app *application) getDeletedUsers(c *gin.Context) {
+	if !database.CanManage(currentOrgRole(c)) {
+		problem(c, http.StatusForbidden, "Only organization admins can see deleted accounts")
+		return
+	}
+
+	users, err := app.models.Users.GetDeletedInOrganization(currentOrgId(c))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve deleted users")
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func (app *application) restoreUser(c *gin.Context) {
+	if !database.CanManage(currentOrgRole(c)) {
+		problem(c, http.StatusForbidden, "Only organization admins can restore accounts")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	restored, err := app.models.Users.Restore(currentOrgId(c), id)
+	if err != nil {
+		respondError(c, err, "Failed to restore user")
+		return
+	}
+	if !restored {
+		problem(c, http.StatusNotFound, "User not found in trash")
+		return
+	}
+
+	user, err := app.models.Users.Get(id)
+	if err != nil || user == nil {
+		serverError(c, err, "Failed to retrieve restored user")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// getDeletedAttendees lists the event's cancelled and removed registrations
+func (app *application) getDeletedAttendees(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+
+	attendees, err := app.models.Attendees.GetDeleted(event.Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve deleted registrations")
+		return
+	}
+	c.JSON(http.StatusOK, attendees)
+}
+
+func (app *application) restoreAttendee(c *gin.Context) {
+	event := app.loadEvent(c)
+	if event == nil || !app.requireEventPermission(c, event, database.PermissionManageAttendees) {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("attendeeId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid attendee ID")
+		return
+	}
+
+	attendee, err := app.models.Attendees.Restore(event.Id, id)
+	if err != nil {
+		respondError(c, err, "Failed to restore registration")
+		return
+	}
+	if attendee == nil {
+		problem(c, http.StatusNotFound, "Registration not found in trash")
+		return
+	}
+	app.auditAttendee(c, database.AuditActionRestore, event, attendee.UserId, database.Diff(nil, attendee))
+	c.JSON(http.StatusOK, attendee)
+}
+
+// purgeDeleted permanently removes whatever has sat in the trash longer than
+// the retention period. Attendances go first so the counts stay meaningful.
+// A failing step does not hold up the others; it is retried on the next run.
+func (app *application) purgeDeleted() error {
+	cutoff := time.Now().Add(-app.trashRetention)
+
+	var failures []error
+	purge := func(what string, fn func(time.Time) (int64, error)) int64 {
+		n, err := fn(cutoff)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", what, err))
+		}
+		return n
+	}
+	attendees := purge("registrations", app.models.Attendees.Purge)
+	events := purge("events", app.models.Events.Purge)
+	users := purge("users", app.models.Users.Purge)
+
+	if attendees+events+users > 0 {
+		log.Printf("🗑️ Purged %d events, %d users and %d registrations from the trash\n", events, users, attendees)
+	}
+	return errors.Join(failures...)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"rest-api-in-gin/internal/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const emailTokenTTL = 24 * time.Hour
+
+// normalizeEmail is the one form an address is stored and looked up in, so
+// Foo@x.com and foo@x.com are the same account
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+type updateProfileRequest struct {
+	Name      *string `json:"name" binding:"omitempty,min=2"`
+	Email     *string `json:"email" binding:"omitempty,email"`
+	AvatarUrl *string `json:"avatarUrl" binding:"omitempty,http_url,max=500"`
+	Bio       *string `json:"bio" binding:"omitempty,max=2000"`
+	Timezone  *string `json:"timezone"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// generateToken returns a random URL-safe token and its SHA-256 hash.
+// Only the hash is stored so a database leak does not expose usable tokens.
+func generateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// currentUser returns the user loaded by AuthMiddleware
+func currentUser(c *gin.Context) *database.User {
+	user, _ := c.Get("user")
+	u, _ := user.(*database.User)
+	return u
+}
+
+func (app *application) getCurrentUser(c *gin.Context) {
+	user, err := app.models.Users.Get(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve user")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (app *application) updateCurrentUser(c *gin.Context) {
+	var input updateProfileRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user := currentUser(c)
+	if input.Name != nil {
+		user.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.AvatarUrl != nil {
+		user.AvatarUrl = *input.AvatarUrl
+	}
+	if input.Bio != nil {
+		user.Bio = *input.Bio
+	}
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil || *input.Timezone == "" {
+			problem(c, http.StatusBadRequest, "Invalid timezone")
+			return
+		}
+		user.Timezone = *input.Timezone
+	}
+
+	newEmail := ""
+	if input.Email != nil && normalizeEmail(*input.Email) != user.Email {
+		newEmail = normalizeEmail(*input.Email)
+		taken, err := app.models.Users.GetByEmail(newEmail)
+		if err != nil {
+			serverError(c, err, "Failed to check email")
+			return
+		}
+		if taken != nil {
+			problem(c, http.StatusConflict, "Email is already in use")
+			return
+		}
+	}
+
+	if err := app.models.Users.Update(user); err != nil {
+		respondError(c, err, "Failed to update profile")
+		return
+	}
+
+	// An email change only takes effect once the new address is verified
+	if newEmail != "" {
+		token, tokenHash, err := generateToken()
+		if err != nil {
+			serverError(c, err, "Failed to generate verification token")
+			return
+		}
+		if err := app.models.Users.SetPendingEmail(user.Id, newEmail, tokenHash, time.Now().Add(emailTokenTTL)); err != nil {
+			respondError(c, err, "Failed to update email")
+			return
+		}
+		body := fmt.Sprintf("Confirm your new email address with this token:\n\n%s\n\nIt expires in 24 hours.", token)
+		if err := app.mailer.Send(newEmail, "Confirm your new email address", body); err != nil {
+			serverError(c, err, "Failed to send verification email")
+			return
+		}
+		user.PendingEmail = newEmail
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (app *application) verifyEmail(c *gin.Context) {
+	var input verifyEmailRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ok, err := app.models.Users.ConfirmPendingEmail(currentUser(c).Id, hashToken(input.Token))
+	if err != nil {
+		respondError(c, err, "Failed to verify email")
+		return
+	}
+	if !ok {
+		problem(c, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	user, err := app.models.Users.Get(currentUser(c).Id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve user")
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (app *application) changePassword(c *gin.Context) {
+	var input changePasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user := currentUser(c)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.CurrentPassword)); err != nil {
+		problem(c, http.StatusUnauthorized, "Current password is incorrect")
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		serverError(c, err, "Failed to hash password")
+		return
+	}
+	if err := app.models.Users.UpdatePassword(user.Id, string(hashedPassword)); err != nil {
+		serverError(c, err, "Failed to update password")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) getUserProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	profile, err := app.models.Users.GetPublicProfile(id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve user")
+		return
+	}
+	if profile == nil {
+		problem(c, http.StatusNotFound, "User not found")
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldError is one failing field of a request. Code names the rule that
+// failed (required, min, email, ...) so clients can react without parsing
+// the message.
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// Report fields under the names clients send
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		if f.Anonymous {
+			return embeddedField
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && t.After(time.Now())
+	})
+}
+
+// bindJSON decodes the request body into dst, rejecting unknown fields, and
+// checks its binding rules. An empty body decodes as {}. It writes a 400 for
+// malformed JSON or a 422 listing every failing field, and returns false.
+func bindJSON(c *gin.Context, dst interface{}) bool {
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil && err != io.EOF {
+		var typeErr *json.UnmarshalTypeError
+		var timeErr *time.ParseError
+		switch {
+		case errors.As(err, &typeErr):
+			validationFailed(c, "Invalid request", fieldError{
+				Field:   typeErr.Field,
+				Code:    "type",
+				Message: "must be " + jsonTypeName(typeErr.Type),
+			})
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			validationFailed(c, "Invalid request", fieldError{
+				Field:   strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`),
+				Code:    "unknown",
+				Message: "is not a recognized field",
+			})
+		case errors.As(err, &timeErr):
+			problem(c, http.StatusBadRequest, "Timestamps must be in RFC 3339 format")
+		default:
+			problem(c, http.StatusBadRequest, "Request body must be valid JSON")
+		}
+		return false
+	}
+	return validateRequest(c, dst)
+}
+
+// validateRequest checks v's binding rules, writing a 422 when any fail
+func validateRequest(c *gin.Context, v interface{}) bool {
+	err := binding.Validator.ValidateStruct(v)
+	if err == nil {
+		return true
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		serverError(c, err, "Failed to validate request")
+		return false
+	}
+	fields := make([]fieldError, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, fieldError{Field: fieldPath(e), Code: e.Tag(), Message: fieldMessage(e)})
+	}
+	validationFailed(c, "Invalid request", fields...)
+	return false
+}
+
+// validationFailed writes the 422 every validation failure shares
+func validationFailed(c *gin.Context, message string, fields ...fieldError) {
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	problem(c, http.StatusUnprocessableEntity, message, gin.H{"fields": fields})
+}
+
+// embeddedField stands in for embedded structs, whose fields JSON flattens
+// into the parent
+const embeddedField = "~"
+
+// fieldPath drops the struct name and embedded structs from the validator's
+// namespace, leaving e.g. items[0].quantity
+func fieldPath(e validator.FieldError) string {
+	_, path, _ := strings.Cut(e.Namespace(), ".")
+	return strings.ReplaceAll(path, embeddedField+".", "")
+}
+
+func fieldMessage(e validator.FieldError) string {
+	unit := ""
+	switch e.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = " items"
+	}
+
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "alpha":
+		return "may only contain letters"
+	case "alphanum":
+		return "may only contain letters and digits"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	case "required_with":
+		return "must be given together with " + e.Param()
+	case "required_without":
+		return "is required without " + e.Param()
+	case "future":
+		return "must be in the future"
+	case "datetime":
+		return "must be an RFC 3339 timestamp"
+	case "len":
+		return "must be exactly " + e.Param() + unit
+	case "min", "gte":
+		if unit != "" {
+			return "must have at least " + e.Param() + unit
+		}
+		return "must be at least " + e.Param()
+	case "max", "lte":
+		if unit != "" {
+			return "must have at most " + e.Param() + unit
+		}
+		return "must be at most " + e.Param()
+	}
+	return "failed the " + e.Tag() + " rule"
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"rest-api-in-gin/internal/database"
+	"rest-api-in-gin/internal/geocode"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultBookingDays = 30
+	maxBookingDays     = 366
+)
+
+type venueRequest struct {
+	Name    string          `json:"name" binding:"required,min=2,max=255"`
+	Address *addressRequest `json:"address"`
+	// Omit both to have them looked up from the address or name
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	// People the whole venue holds; omit when unknown
+	Capacity      *int                 `json:"capacity" binding:"omitempty,min=1"`
+	Accessibility accessibilityRequest `json:"accessibility"`
+}
+
+type accessibilityRequest struct {
+	StepFreeAccess    bool   `json:"stepFreeAccess"`
+	AccessibleToilets bool   `json:"accessibleToilets"`
+	HearingLoop       bool   `json:"hearingLoop"`
+	Notes             string `json:"notes" binding:"max=2000"`
+}
+
+type roomRequest struct {
+	Name           string `json:"name" binding:"required,min=1,max=255"`
+	Capacity       *int   `json:"capacity" binding:"omitempty,min=1"`
+	Floor          string `json:"floor" binding:"max=50"`
+	StepFreeAccess bool   `json:"stepFreeAccess"`
+}
+
+func (r *venueRequest) applyTo(v *database.Venue) {
+	v.Name = strings.TrimSpace(r.Name)
+	v.Address = database.Address{}
+	if address := r.Address.toAddress(); address != nil {
+		v.Address = *address
+	}
+	v.Latitude = r.Latitude
+	v.Longitude = r.Longitude
+	v.Capacity = r.Capacity
+	v.Accessibility = database.Accessibility{
+		StepFreeAccess:    r.Accessibility.StepFreeAccess,
+		AccessibleToilets: r.Accessibility.AccessibleToilets,
+		HearingLoop:       r.Accessibility.HearingLoop,
+		Notes:             strings.TrimSpace(r.Accessibility.Notes),
+	}
+}
+
+func (r *roomRequest) applyTo(room *database.Room) {
+	room.Name = strings.TrimSpace(r.Name)
+	room.Capacity = r.Capacity
+	room.Floor = strings.TrimSpace(r.Floor)
+	room.StepFreeAccess = r.StepFreeAccess
+}
+
+// requireMember writes a 403 and returns false unless the caller belongs to
+// the current organization
+func requireMember(c *gin.Context) bool {
+	if currentOrgRole(c) == "" {
+		problem(c, http.StatusForbidden, "You are not a member of this organization")
+		return false
+	}
+	return true
+}
+
+// requireVenueEditor lets the venue's creator and organization admins change it
+func requireVenueEditor(c *gin.Context, venue *database.Venue) bool {
+	if database.CanManage(currentOrgRole(c)) {
+		return true
+	}
+	if venue.CreatedBy != nil && *venue.CreatedBy == currentUser(c).Id {
+		return true
+	}
+	problem(c, http.StatusForbidden, "Only the venue's creator or an organization admin can change it")
+	return false
+}
+
+// loadVenue parses :venueId within the current organization. It writes the
+// error response itself and returns nil when the venue cannot be used.
+func (app *application) loadVenue(c *gin.Context) *database.Venue {
+	if !requireMember(c) {
+		return nil
+	}
+	id, err := strconv.Atoi(c.Param("venueId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid venue ID")
+		return nil
+	}
+	venue, err := app.models.Venues.Get(currentOrgId(c), id)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve venue")
+		return nil
+	}
+	if venue == nil {
+		problem(c, http.StatusNotFound, "Venue not found")
+		return nil
+	}
+	return venue
+}
+
+// loadRoom parses :roomId within an already loaded venue
+func (app *application) loadRoom(c *gin.Context, venue *database.Venue) *database.Room {
+	id, err := strconv.Atoi(c.Param("roomId"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, "Invalid room ID")
+		return nil
+	}
+	for _, room := range venue.Rooms {
+		if room.Id == id {
+			return room
+		}
+	}
+	problem(c, http.StatusNotFound, "Room not found")
+	return nil
+}
+
+// locateVenue geocodes a venue saved without coordinates. A venue that
+// cannot be placed is saved without them.
+func (app *application) locateVenue(ctx context.Context, venue *database.Venue) {
+	if venue.Latitude != nil && venue.Longitude != nil {
+		return
+	}
+	place, err := app.geocoder.Geocode(ctx, geocodeQuery(&venue.Address, venue.Name))
+	if err != nil {
+		if !errors.Is(err, geocode.ErrNotFound) {
+			log.Printf("❌ Could not geocode venue %q: %v\n", venue.Name, err)
+		}
+		return
+	}
+	venue.Latitude, venue.Longitude = &place.Latitude, &place.Longitude
+}
+
+// checkRoomCapacity reports a room that would hold more than its venue
+func checkRoomCapacity(venue *database.Venue, room *database.Room) []fieldError {
+	if venue.Capacity != nil && room.Capacity != nil && *room.Capacity > *venue.Capacity {
+		return []fieldError{{Field: "capacity", Code: "max", Message: "must be at most the venue's capacity of " + strconv.Itoa(*venue.Capacity)}}
+	}
+	return nil
+}
+
+func (app *application) getVenues(c *gin.Context) {
+	if !requireMember(c) {
+		return
+	}
+	venues, err := app.models.Venues.GetByOrganization(currentOrgId(c))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve venues")
+		return
+	}
+	c.JSON(http.StatusOK, venues)
+}
+
+func (app *application) getVenue(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil {
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+func (app *application) createVenue(c *gin.Context) {
+	if !requireMember(c) {
+		return
+	}
+	var input venueRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userId := currentUser(c).Id
+	venue := database.Venue{OrganizationId: currentOrgId(c), CreatedBy: &userId}
+	input.applyTo(&venue)
+	app.locateVenue(c.Request.Context(), &venue)
+
+	if err := app.models.Venues.Insert(&venue); err != nil {
+		respondError(c, err, "Failed to create venue")
+		return
+	}
+	venue.Rooms = []*database.Room{}
+	c.JSON(http.StatusCreated, venue)
+}
+
+// updateVenue replaces the venue's details. Events already held there keep
+// the location they were saved with.
+func (app *application) updateVenue(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil || !requireVenueEditor(c, venue) {
+		return
+	}
+	var input venueRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	previous := *venue
+	input.applyTo(venue)
+	if input.Latitude == nil && previous.Address != venue.Address {
+		// Moved without new coordinates, so look them up again
+		venue.Latitude, venue.Longitude = nil, nil
+	} else if input.Latitude == nil {
+		venue.Latitude, venue.Longitude = previous.Latitude, previous.Longitude
+	}
+	app.locateVenue(c.Request.Context(), venue)
+
+	for _, room := range venue.Rooms {
+		if venue.Capacity != nil && room.Capacity != nil && *room.Capacity > *venue.Capacity {
+			validationFailed(c, "Invalid venue", fieldError{
+				Field:   "capacity",
+				Code:    "min",
+				Message: "must be at least the " + strconv.Itoa(*room.Capacity) + " that room " + room.Name + " holds",
+			})
+			return
+		}
+	}
+
+	if err := app.models.Venues.Update(venue); err != nil {
+		respondError(c, err, "Failed to update venue")
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+func (app *application) deleteVenue(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil || !requireVenueEditor(c, venue) {
+		return
+	}
+	if err := app.models.Venues.Delete(venue.OrganizationId, venue.Id); err != nil {
+		respondError(c, err, "Failed to delete venue")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (app *application) createRoom(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil || !requireVenueEditor(c, venue) {
+		return
+	}
+	var input roomRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	room := database.Room{VenueId: venue.Id}
+	input.applyTo(&room)
+	if fields := checkRoomCapacity(venue, &room); fields != nil {
+		validationFailed(c, "Invalid room", fields...)
+		return
+	}
+
+	if err := app.models.Venues.InsertRoom(&room); err != nil {
+		respondError(c, err, "Failed to create room")
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+func (app *application) updateRoom(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil || !requireVenueEditor(c, venue) {
+		return
+	}
+	room := app.loadRoom(c, venue)
+	if room == nil {
+		return
+	}
+	var input roomRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	input.applyTo(room)
+	if fields := checkRoomCapacity(venue, room); fields != nil {
+		validationFailed(c, "Invalid room", fields...)
+		return
+	}
+
+	if err := app.models.Venues.UpdateRoom(room); err != nil {
+		respondError(c, err, "Failed to update room")
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+func (app *application) deleteRoom(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil || !requireVenueEditor(c, venue) {
+		return
+	}
+	room := app.loadRoom(c, venue)
+	if room == nil {
+		return
+	}
+	if err := app.models.Venues.DeleteRoom(venue.Id, room.Id); err != nil {
+		respondError(c, err, "Failed to delete room")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// getVenueBookings lists when the venue's rooms are taken between ?from=
+// and ?to=, by default over the next 30 days. Names of events the caller
+// cannot see are left out.
+func (app *application) getVenueBookings(c *gin.Context) {
+	venue := app.loadVenue(c)
+	if venue == nil {
+		return
+	}
+
+	from := time.Now().UTC()
+	var to time.Time
+	if v := c.Query("from"); v != "" {
+		t, ok := parseSearchDate(v, false)
+		if !ok {
+			problem(c, http.StatusBadRequest, "from must be a date or an RFC 3339 timestamp")
+			return
+		}
+		from = t.UTC()
+	}
+	to = from.AddDate(0, 0, defaultBookingDays)
+	if v := c.Query("to"); v != "" {
+		t, ok := parseSearchDate(v, true)
+		if !ok {
+			problem(c, http.StatusBadRequest, "to must be a date or an RFC 3339 timestamp")
+			return
+		}
+		to = t.UTC()
+	}
+	if !to.After(from) {
+		problem(c, http.StatusBadRequest, "to must be after from")
+		return
+	}
+	if to.Sub(from) > maxBookingDays*24*time.Hour {
+		problem(c, http.StatusBadRequest, "from and to may be at most "+strconv.Itoa(maxBookingDays)+" days apart")
+		return
+	}
+
+	bookings, err := app.models.Venues.GetBookings(venue.Id, viewerId(c), from, to, database.CanManage(currentOrgRole(c)))
+	if err != nil {
+		serverError(c, err, "Failed to retrieve bookings")
+		return
+	}
+	c.JSON(http.StatusOK, bookings)
+}
+
+// placeAtVenue checks the event's venue and room and fills in what the
+// venue knows: the location, address and coordinates left out or carried
+// over from a previous venue, and a capacity up to the room's. A room
+// already taken at that time is refused with the conflicting bookings.
+// existing is nil for new events. It writes the error response itself.
+func (app *application) placeAtVenue(c *gin.Context, existing, event *database.Event) bool {
+	if event.VenueId == nil {
+		if event.RoomId != nil {
+			validationFailed(c, "Invalid event", fieldError{Field: "roomId", Code: "required_with", Message: "must be given together with VenueId"})
+			return false
+		}
+		return true
+	}
+
+	venue, err := app.models.Venues.Get(event.OrganizationId, *event.VenueId)
+	if err != nil {
+		serverError(c, err, "Failed to retrieve venue")
+		return false
+	}
+	if venue == nil {
+		validationFailed(c, "Invalid event", fieldError{Field: "venueId", Code: "reference", Message: "no such venue in this organization"})
+		return false
+	}
+	var room *database.Room
+	if event.RoomId != nil {
+		for _, r := range venue.Rooms {
+			if r.Id == *event.RoomId {
+				room = r
+			}
+		}
+		if room == nil {
+			validationFailed(c, "Invalid event", fieldError{Field: "roomId", Code: "reference", Message: "no such room in this venue"})
+			return false
+		}
+	}
+
+	// Values the previous venue filled in follow the event to the new one
+	moved := existing != nil && (existing.VenueId == nil || *existing.VenueId != venue.Id)
+	if event.Location == "" || moved && event.Location == existing.Location {
+		event.Location = venue.Name
+		if venue.Address.City != "" {
+			event.Location += ", " + venue.Address.City
+		}
+	}
+	if event.Address == nil || moved && reflect.DeepEqual(event.Address, existing.Address) {
+		event.Address = nil
+		if venue.Address != (database.Address{}) {
+			address := venue.Address
+			event.Address = &address
+		}
+	}
+	if event.Latitude == nil || moved && reflect.DeepEqual(event.Latitude, existing.Latitude) && reflect.DeepEqual(event.Longitude, existing.Longitude) {
+		event.Latitude, event.Longitude = venue.Latitude, venue.Longitude
+	}
+
+	limit := venue.Capacity
+	if room != nil && room.Capacity != nil {
+		limit = room.Capacity
+	}
+	if event.Capacity == nil {
+		event.Capacity = limit
+	} else if limit != nil && *event.Capacity > *limit {
+		validationFailed(c, "Invalid event", fieldError{Field: "capacity", Code: "max", Message: "must be at most the venue's capacity of " + strconv.Itoa(*limit)})
+		return false
+	}
+
+	if room == nil || event.Status == database.EventStatusCancelled {
+		return true
+	}
+	conflicts, err := app.models.Venues.GetRoomConflicts(room.Id, event.Id, event.DateTime, event.EndsAt)
+	if err != nil {
+		serverError(c, err, "Failed to check room bookings")
+		return false
+	}
+	if len(conflicts) > 0 {
+		for _, b := range conflicts {
+			if ok, err := app.canSeeBooking(c, b); err != nil || !ok {
+				b.EventName = ""
+			}
+		}
+		writeProblem(c, &apiError{
+			Status:     http.StatusConflict,
+			Type:       "room-double-booked",
+			Detail:     room.Name + " is already booked at that time",
+			Extensions: gin.H{"conflicts": conflicts},
+		})
+		return false
+	}
+	return true
+}
+
+// canSeeBooking reports whether the caller may see which event holds a booking
+func (app *application) canSeeBooking(c *gin.Context, b *database.RoomBooking) (bool, error) {
+	if database.CanManage(currentOrgRole(c)) {
+		return true, nil
+	}
+	event, err := app.models.Events.Get(currentOrgId(c), b.EventId)
+	if err != nil || event == nil {
+		return false, err
+	}
+	return app.canViewEvent(c, event)
+}
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_users_email_token_hash;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS email_verified,
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS email_token_hash,
+    DROP COLUMN IF EXISTS email_token_expires_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS email_token_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS email_token_expires_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_email_token_hash ON users(email_token_hash);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_deletion_mode_check;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deletion_mode,
+    DROP COLUMN IF EXISTS deletion_scheduled_at,
+    DROP COLUMN IF EXISTS anonymized_at;
@@ -0,0 +1,10 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_mode VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_deletion_mode_check
+    CHECK (deletion_mode IS NULL OR deletion_mode IN ('anonymize', 'cascade'));
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at);
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(organization_id, user_id),
+    CHECK (role IN ('owner', 'admin', 'member'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- Existing users and events move into a shared default organization
+INSERT INTO organizations (name, slug) VALUES ('Default', 'default') ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT o.id, u.id, 'member'
+FROM organizations o CROSS JOIN users u
+WHERE o.slug = 'default'
+ON CONFLICT (organization_id, user_id) DO NOTHING;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+
+UPDATE events SET organization_id = (SELECT id FROM organizations WHERE slug = 'default')
+WHERE organization_id IS NULL;
+
+ALTER TABLE events ALTER COLUMN organization_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_organization_id ON events(organization_id);
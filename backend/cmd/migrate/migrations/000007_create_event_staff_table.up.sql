@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS event_staff (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(event_id, user_id),
+    CHECK (role IN ('co_organizer', 'attendee_manager', 'checkin'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_staff_user_id ON event_staff(user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS event_invitations;
+DROP INDEX IF EXISTS idx_events_visibility;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_visibility_check;
+ALTER TABLE events DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,25 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public';
+
+ALTER TABLE events
+    ADD CONSTRAINT events_visibility_check CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+CREATE INDEX IF NOT EXISTS idx_events_visibility ON events(visibility);
+
+CREATE TABLE IF NOT EXISTS event_invitations (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255),
+    max_uses INTEGER,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (max_uses IS NULL OR max_uses > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_invitations_event_id ON event_invitations(event_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_attendees_event_status;
+ALTER TABLE attendees DROP CONSTRAINT IF EXISTS attendees_status_check;
+
+ALTER TABLE attendees
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS status_message,
+    DROP COLUMN IF EXISTS decided_by,
+    DROP COLUMN IF EXISTS decided_at;
+
+ALTER TABLE events DROP COLUMN IF EXISTS requires_approval;
@@ -0,0 +1,13 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE attendees
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'confirmed',
+    ADD COLUMN IF NOT EXISTS status_message TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS decided_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+
+ALTER TABLE attendees
+    ADD CONSTRAINT attendees_status_check CHECK (status IN ('pending', 'confirmed', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS idx_attendees_event_status ON attendees(event_id, status);
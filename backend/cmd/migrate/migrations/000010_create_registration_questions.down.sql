@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attendee_answers;
+DROP TABLE IF EXISTS event_questions;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS event_questions (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    label VARCHAR(500) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    options JSONB NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    CHECK (type IN ('text', 'choice', 'multi_choice', 'number'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_questions_event_id ON event_questions(event_id);
+
+CREATE TABLE IF NOT EXISTS attendee_answers (
+    id SERIAL PRIMARY KEY,
+    attendee_id INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    value JSONB NOT NULL,
+    FOREIGN KEY (attendee_id) REFERENCES attendees(id) ON DELETE CASCADE,
+    FOREIGN KEY (question_id) REFERENCES event_questions(id) ON DELETE CASCADE,
+    UNIQUE(attendee_id, question_id)
+);
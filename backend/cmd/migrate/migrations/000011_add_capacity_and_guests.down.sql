@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS attendee_guests;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS capacity,
+    DROP COLUMN IF EXISTS max_guests;
@@ -0,0 +1,14 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS capacity INTEGER CHECK (capacity IS NULL OR capacity > 0),
+    ADD COLUMN IF NOT EXISTS max_guests INTEGER NOT NULL DEFAULT 0 CHECK (max_guests >= 0);
+
+CREATE TABLE IF NOT EXISTS attendee_guests (
+    id SERIAL PRIMARY KEY,
+    attendee_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (attendee_id) REFERENCES attendees(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendee_guests_attendee_id ON attendee_guests(attendee_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS check_in_scans;
+
+ALTER TABLE attendees
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS checked_in_by;
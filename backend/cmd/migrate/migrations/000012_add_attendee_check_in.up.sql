@@ -0,0 +1,18 @@
+ALTER TABLE attendees
+    ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_in_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS check_in_scans (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    attendee_id INTEGER,
+    scanned_by INTEGER,
+    result VARCHAR(20) NOT NULL,
+    scanned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (attendee_id) REFERENCES attendees(id) ON DELETE CASCADE,
+    FOREIGN KEY (scanned_by) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (result IN ('checked_in', 'duplicate', 'invalid'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_in_scans_event_id ON check_in_scans(event_id);
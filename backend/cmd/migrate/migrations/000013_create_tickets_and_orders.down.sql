@@ -0,0 +1,4 @@
+ALTER TABLE attendees DROP COLUMN IF EXISTS order_id;
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS ticket_types;
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS ticket_types (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price_cents BIGINT NOT NULL DEFAULT 0 CHECK (price_cents >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    sales_start TIMESTAMP,
+    sales_end TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    CHECK (sales_start IS NULL OR sales_end IS NULL OR sales_start < sales_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_types_event_id ON ticket_types(event_id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_cents BIGINT NOT NULL CHECK (total_cents >= 0),
+    currency CHAR(3) NOT NULL,
+    registration JSONB NOT NULL DEFAULT '{}',
+    payment_provider VARCHAR(50) NOT NULL DEFAULT '',
+    payment_reference VARCHAR(255),
+    expires_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (status IN ('pending', 'paid', 'failed', 'expired', 'cancelled', 'refunded'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_event_status ON orders(event_id, status);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_payment_reference ON orders(payment_provider, payment_reference)
+    WHERE payment_reference IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    ticket_type_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price_cents BIGINT NOT NULL CHECK (unit_price_cents >= 0),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket_type_id) REFERENCES ticket_types(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_ticket_type_id ON order_items(ticket_type_id);
+
+ALTER TABLE attendees
+    ADD COLUMN IF NOT EXISTS order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_orders_promo_code_id;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS discount_cents,
+    DROP COLUMN IF EXISTS promo_code_id;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL,
+    discount_value BIGINT NOT NULL CHECK (discount_value > 0),
+    -- Empty means the code applies to every ticket type of the event
+    ticket_type_ids INTEGER[] NOT NULL DEFAULT '{}',
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0),
+    starts_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    UNIQUE (event_id, code),
+    CHECK (discount_type IN ('percent', 'fixed')),
+    CHECK (discount_type <> 'percent' OR discount_value <= 100),
+    CHECK (starts_at IS NULL OR expires_at IS NULL OR starts_at < expires_at)
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS promo_code_id INTEGER REFERENCES promo_codes(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS discount_cents BIGINT NOT NULL DEFAULT 0 CHECK (discount_cents >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_orders_promo_code_id ON orders(promo_code_id) WHERE promo_code_id IS NOT NULL;
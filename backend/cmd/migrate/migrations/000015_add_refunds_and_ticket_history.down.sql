@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS ticket_history;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS refunded_cents;
+ALTER TABLE events
+    DROP COLUMN IF EXISTS refund_cutoff_hours,
+    DROP COLUMN IF EXISTS refund_percent;
@@ -0,0 +1,30 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS refund_percent INTEGER NOT NULL DEFAULT 100 CHECK (refund_percent BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS refund_cutoff_hours INTEGER NOT NULL DEFAULT 0 CHECK (refund_cutoff_hours >= 0);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS refunded_cents BIGINT NOT NULL DEFAULT 0 CHECK (refunded_cents >= 0),
+    ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS ticket_history (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    order_id INTEGER,
+    action VARCHAR(20) NOT NULL,
+    from_user_id INTEGER,
+    to_user_id INTEGER,
+    actor_id INTEGER,
+    refund_cents BIGINT NOT NULL DEFAULT 0,
+    refund_reference VARCHAR(255),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL,
+    FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (action IN ('cancelled', 'removed', 'transferred'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_history_event_id ON ticket_history(event_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_history_order_id ON ticket_history(order_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_events_organization_status;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
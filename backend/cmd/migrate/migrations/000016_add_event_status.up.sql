@@ -0,0 +1,9 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+
+ALTER TABLE events
+    ADD CONSTRAINT events_status_check CHECK (status IN ('draft', 'published', 'postponed', 'cancelled', 'completed'));
+
+CREATE INDEX IF NOT EXISTS idx_events_organization_status ON events(organization_id, status);
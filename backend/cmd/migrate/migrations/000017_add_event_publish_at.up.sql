@@ -0,0 +1,5 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_events_publish_at ON events(publish_at)
+    WHERE status = 'draft' AND publish_at IS NOT NULL;
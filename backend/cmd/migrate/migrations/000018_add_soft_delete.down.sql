@@ -0,0 +1,19 @@
+DELETE FROM attendees WHERE deleted_at IS NOT NULL;
+DELETE FROM events WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_attendees_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_events_deleted_at;
+
+DROP INDEX IF EXISTS idx_users_email_active;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_attendees_user_event_active;
+ALTER TABLE attendees ADD CONSTRAINT attendees_user_id_event_id_key UNIQUE (user_id, event_id);
+
+ALTER TABLE attendees DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE events
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
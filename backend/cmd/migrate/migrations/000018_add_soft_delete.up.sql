@@ -0,0 +1,22 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE attendees
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Deleted rows must not block signing up again or reusing an email address
+ALTER TABLE attendees DROP CONSTRAINT IF EXISTS attendees_user_id_event_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendees_user_event_active ON attendees(user_id, event_id)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users(email)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attendees_deleted_at ON attendees(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Rows outlive the events and attendees they describe, so only the
+-- organization and actor are foreign keys
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    event_id INTEGER,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    actor_id INTEGER,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (entity_type IN ('event', 'attendee'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_event_id ON audit_log(event_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_organization_id ON audit_log(organization_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
@@ -0,0 +1,3 @@
+-- Bumped on every write so clients can detect concurrent edits
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
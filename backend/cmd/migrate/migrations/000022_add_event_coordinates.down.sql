@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_events_coordinates;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_coordinates_check,
+    DROP CONSTRAINT IF EXISTS events_longitude_check,
+    DROP CONSTRAINT IF EXISTS events_latitude_check;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS country,
+    DROP COLUMN IF EXISTS postal_code,
+    DROP COLUMN IF EXISTS region,
+    DROP COLUMN IF EXISTS city,
+    DROP COLUMN IF EXISTS street,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
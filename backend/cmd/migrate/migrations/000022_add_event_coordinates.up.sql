@@ -0,0 +1,18 @@
+-- Coordinates and an optional structured address; location stays the
+-- free-text label shown to attendees
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS street VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS city VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS region VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS postal_code VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS country VARCHAR(100);
+
+ALTER TABLE events
+    ADD CONSTRAINT events_latitude_check CHECK (latitude BETWEEN -90 AND 90),
+    ADD CONSTRAINT events_longitude_check CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT events_coordinates_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+-- Narrows nearby searches to a band of latitude before distances are computed
+CREATE INDEX IF NOT EXISTS idx_events_coordinates ON events(latitude, longitude) WHERE latitude IS NOT NULL;
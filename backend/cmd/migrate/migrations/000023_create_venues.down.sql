@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_venue_rooms_venue_id;
+DROP INDEX IF EXISTS idx_events_venue_id;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_room_booking_excl,
+    DROP CONSTRAINT IF EXISTS events_ends_at_check;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS ends_at,
+    DROP COLUMN IF EXISTS room_id,
+    DROP COLUMN IF EXISTS venue_id;
+
+DROP TABLE IF EXISTS venue_rooms;
+DROP TABLE IF EXISTS venues;
@@ -0,0 +1,61 @@
+-- Lets one exclusion constraint compare room ids and time ranges
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS venues (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    street VARCHAR(255),
+    city VARCHAR(100),
+    region VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(100),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    capacity INTEGER,
+    step_free_access BOOLEAN NOT NULL DEFAULT FALSE,
+    accessible_toilets BOOLEAN NOT NULL DEFAULT FALSE,
+    hearing_loop BOOLEAN NOT NULL DEFAULT FALSE,
+    accessibility_notes TEXT NOT NULL DEFAULT '',
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE (organization_id, name),
+    CONSTRAINT venues_capacity_check CHECK (capacity > 0),
+    CONSTRAINT venues_latitude_check CHECK (latitude BETWEEN -90 AND 90),
+    CONSTRAINT venues_longitude_check CHECK (longitude BETWEEN -180 AND 180),
+    CONSTRAINT venues_coordinates_check CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS venue_rooms (
+    id SERIAL PRIMARY KEY,
+    venue_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    capacity INTEGER,
+    floor VARCHAR(50) NOT NULL DEFAULT '',
+    step_free_access BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    UNIQUE (venue_id, name),
+    CONSTRAINT venue_rooms_capacity_check CHECK (capacity > 0)
+);
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES venue_rooms(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS ends_at TIMESTAMP;
+
+ALTER TABLE events
+    ADD CONSTRAINT events_ends_at_check CHECK (ends_at > datetime);
+
+-- A room holds one live event at a time. Events without an end are taken
+-- to last two hours.
+ALTER TABLE events
+    ADD CONSTRAINT events_room_booking_excl EXCLUDE USING gist (
+        room_id WITH =,
+        tsrange(datetime, COALESCE(ends_at, datetime + INTERVAL '2 hours')) WITH &&
+    ) WHERE (room_id IS NOT NULL AND deleted_at IS NULL AND status <> 'cancelled');
+
+CREATE INDEX IF NOT EXISTS idx_events_venue_id ON events(venue_id) WHERE venue_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_venue_rooms_venue_id ON venue_rooms(venue_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_events_tags;
+DROP INDEX IF EXISTS idx_events_category_id;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS event_categories;
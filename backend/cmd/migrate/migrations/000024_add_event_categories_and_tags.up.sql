@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS event_categories (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- Browsing pages list categories in this order, then by name
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    UNIQUE (organization_id, name),
+    UNIQUE (organization_id, slug)
+);
+
+-- Tags are free-form and stored normalized, e.g. live-music
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES event_categories(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_events_category_id ON events(category_id) WHERE category_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_tags ON events USING GIN (tags);
@@ -0,0 +1,12 @@
+-- Addresses are stored lower-cased from now on. Accounts whose lower-cased
+-- address collides with another live account are left alone to be merged by hand
+UPDATE users u
+SET email = LOWER(u.email)
+WHERE u.email <> LOWER(u.email)
+  AND NOT EXISTS (
+      SELECT 1 FROM users o
+      WHERE o.id <> u.id AND o.deleted_at IS NULL AND LOWER(o.email) = LOWER(u.email)
+  );
+
+UPDATE users SET pending_email = LOWER(pending_email)
+WHERE pending_email IS NOT NULL AND pending_email <> LOWER(pending_email);
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -11,28 +12,103 @@ type AttendeeModel struct {
 }
 
 type Attendee struct {
-	Id      int `json:"Id"`
-	UserId  int `json:"UserId"`
-	EventId int `json:"EventId"`
+	Id            int    `json:"Id"`
+	UserId        int    `json:"UserId"`
+	EventId       int    `json:"EventId"`
+	Status        string `json:"Status"`
+	StatusMessage string `json:"StatusMessage,omitempty"`
+
+	CheckedInAt *time.Time `json:"CheckedInAt,omitempty"`
+	// Set when the registration came from a ticket purchase
+	OrderId *int `json:"OrderId,omitempty"`
+}
+
+const (
+	AttendeeStatusPending   = "pending"
+	AttendeeStatusConfirmed = "confirmed"
+	AttendeeStatusRejected  = "rejected"
+)
+
+var (
+	// ErrEventFull is returned when a registration would exceed the event's capacity
+	ErrEventFull = errors.New("event is full")
+	// ErrNotRestorable is returned when a deleted registration cannot come back
+	ErrNotRestorable = errors.New("registration was refunded, transferred or belongs to a deleted user")
+)
+
+// DeletedAttendee is a registration waiting in an event's trash
+type DeletedAttendee struct {
+	Id         int       `json:"Id"`
+	UserId     int       `json:"UserId"`
+	Name       string    `json:"Name"`
+	Email      string    `json:"Email"`
+	Status     string    `json:"Status"`
+	GuestCount int       `json:"GuestCount"`
+	OrderId    *int      `json:"OrderId,omitempty"`
+	DeletedAt  time.Time `json:"DeletedAt"`
 }
 
 // ✅ Insert — PostgreSQL-compatible (uses $1, $2 and RETURNING id)
 func (m *AttendeeModel) Insert(attendee *Attendee) (int, error) {
+	return m.Register(attendee, nil, nil)
+}
+
+// ✅ Register — adds the attendee with their answers and guests in one
+// transaction, refusing with ErrEventFull when capacity would be exceeded
+func (m *AttendeeModel) Register(attendee *Attendee, answers Answers, guests []Guest) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := insertAttendee(ctx, tx, attendee, answers, guests, true); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return attendee.Id, nil
+}
+
+// insertAttendee does the work of a registration inside the caller's
+// transaction. The event row is locked so concurrent sign-ups cannot both
+// take the last seat. Paid orders skip the capacity check because their
+// seats were reserved at checkout.
+func insertAttendee(ctx context.Context, tx *sql.Tx, attendee *Attendee, answers Answers, guests []Guest, checkCapacity bool) error {
+	if checkCapacity {
+		if err := reserveSeats(ctx, tx, attendee.EventId, 1+len(guests)); err != nil {
+			return err
+		}
+	}
+
 	query := `
-		INSERT INTO attendees (event_id, user_id)
-		VALUES ($1, $2)
+		INSERT INTO attendees (event_id, user_id, status, order_id)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id
 	`
 
-	err := m.DB.QueryRowContext(ctx, query, attendee.EventId, attendee.UserId).Scan(&attendee.Id)
+	if attendee.Status == "" {
+		attendee.Status = AttendeeStatusConfirmed
+	}
+
+	err := tx.QueryRowContext(ctx, query, attendee.EventId, attendee.UserId, attendee.Status, attendee.OrderId).Scan(&attendee.Id)
 	if err != nil {
-		return 0, err
+		// Only one live registration per user and event
+		if err = translateError(err); errors.Is(err, ErrDuplicate) {
+			return ErrAlreadyRegistered
+		}
+		return err
 	}
 
-	return attendee.Id, nil
+	if err := insertAnswers(ctx, tx, attendee.Id, answers); err != nil {
+		return err
+	}
+	return insertGuests(ctx, tx, attendee.Id, guests)
 }
 
 // ✅ GetByEventAndAttendee — PostgreSQL placeholders ($1, $2)
@@ -41,9 +117,9 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, userId int) (*Attendee, e
 	defer cancel()
 
 	query := `
-		SELECT id, event_id, user_id
+		SELECT id, event_id, user_id, status, status_message, checked_in_at, order_id
 		FROM attendees
-		WHERE event_id = $1 AND user_id = $2
+		WHERE event_id = $1 AND user_id = $2 AND deleted_at IS NULL
 	`
 
 	var attendee Attendee
@@ -51,6 +127,10 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, userId int) (*Attendee, e
 		&attendee.Id,
 		&attendee.EventId,
 		&attendee.UserId,
+		&attendee.Status,
+		&attendee.StatusMessage,
+		&attendee.CheckedInAt,
+		&attendee.OrderId,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -61,90 +141,279 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, userId int) (*Attendee, e
 	return &attendee, nil
 }
 
-// ✅ GetAttendeesByEvent — PostgreSQL-compatible
-func (m *AttendeeModel) GetAttendeesByEvent(eventId int) ([]*User, error) {
+// EventAttendee is a confirmed attendee as listed publicly
+type EventAttendee struct {
+	Id         int    `json:"Id"`
+	Name       string `json:"Name"`
+	Email      string `json:"Email"`
+	GuestCount int    `json:"GuestCount"`
+}
+
+// ✅ GetAttendeesByEvent — confirmed attendees only, plus the headcount
+// including their guests
+func (m *AttendeeModel) GetAttendeesByEvent(eventId int) ([]*EventAttendee, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT u.id, u.name, u.email
+		SELECT u.id, u.name, u.email,
+			(SELECT COUNT(*) FROM attendee_guests g WHERE g.attendee_id = a.id)
 		FROM users u
 		JOIN attendees a ON u.id = a.user_id
-		WHERE a.event_id = $1
+		WHERE a.event_id = $1 AND a.status = 'confirmed' AND a.deleted_at IS NULL AND u.deleted_at IS NULL
 	`
 
 	rows, err := m.DB.QueryContext(ctx, query, eventId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []*User
+	attendees := make([]*EventAttendee, 0)
+	headcount := 0
 	for rows.Next() {
-		var user User
-		if err := rows.Scan(&user.Id, &user.Name, &user.Email); err != nil {
-			return nil, err
+		var attendee EventAttendee
+		if err := rows.Scan(&attendee.Id, &attendee.Name, &attendee.Email, &attendee.GuestCount); err != nil {
+			return nil, 0, err
 		}
-		users = append(users, &user)
+		headcount += 1 + attendee.GuestCount
+		attendees = append(attendees, &attendee)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	return attendees, headcount, nil
+}
+
+// ✅ GetHeadcount — seats taken by pending and confirmed registrations and their guests
+func (m *AttendeeModel) GetHeadcount(eventId int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	return countHeadcount(ctx, tx, eventId)
+}
+
+// reserveSeats locks the event row and returns ErrEventFull unless seats more
+// people fit, or ErrEventClosed if the event is not taking registrations.
+// Tickets held by unexpired pending orders count as taken.
+func reserveSeats(ctx context.Context, tx *sql.Tx, eventId, seats int) error {
+	var capacity sql.NullInt64
+	var status string
+	var deleted bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT capacity, status, deleted_at IS NOT NULL FROM events WHERE id = $1 FOR UPDATE`,
+		eventId,
+	).Scan(&capacity, &status, &deleted)
+	if err != nil {
+		return err
+	}
+	if deleted || (status != EventStatusPublished && status != EventStatusPostponed) {
+		return ErrEventClosed
+	}
+	if !capacity.Valid {
+		return nil
+	}
+
+	headcount, err := countHeadcount(ctx, tx, eventId)
+	if err != nil {
+		return err
+	}
+	var held int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(oi.quantity), 0)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.event_id = $1 AND o.status = 'pending' AND o.expires_at > NOW()
+	`, eventId).Scan(&held)
+	if err != nil {
+		return err
+	}
+
+	if headcount+held+seats > int(capacity.Int64) {
+		return ErrEventFull
 	}
-	return users, nil
+	return nil
 }
 
-// ✅ Delete — PostgreSQL-compatible
+func countHeadcount(ctx context.Context, tx *sql.Tx, eventId int) (int, error) {
+	query := `
+		SELECT COUNT(*) + COALESCE(SUM(
+			(SELECT COUNT(*) FROM attendee_guests g WHERE g.attendee_id = a.id)
+		), 0)
+		FROM attendees a
+		WHERE a.event_id = $1 AND a.status IN ('pending', 'confirmed') AND a.deleted_at IS NULL
+	`
+	var headcount int
+	err := tx.QueryRowContext(ctx, query, eventId).Scan(&headcount)
+	return headcount, err
+}
+
+// ✅ Delete — soft-deletes the registration; it is purged after the retention period
 func (m *AttendeeModel) Delete(userId, eventID int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		DELETE FROM attendees
-		WHERE user_id = $1 AND event_id = $2
+		UPDATE attendees
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND event_id = $2 AND deleted_at IS NULL
 	`
 
 	_, err := m.DB.ExecContext(ctx, query, userId, eventID)
 	return err
 }
 
-// ✅ GetEventsByAttendee — PostgreSQL-compatible
+// ✅ GetEventsByAttendee — every event a user attends, across all organizations
 func (m *AttendeeModel) GetEventsByAttendee(attendeeId int) ([]*Event, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		JOIN attendees a ON e.id = a.event_id
+		WHERE a.user_id = $1 AND a.deleted_at IS NULL AND e.deleted_at IS NULL
+	`
+	return queryEvents(m.DB, query, attendeeId)
+}
 
+// ✅ GetEventsByAttendeeInOrganization — events a user attends within one
+// organization, limited to those the viewer may see
+func (m *AttendeeModel) GetEventsByAttendeeInOrganization(orgId, attendeeId, viewerId int) ([]*Event, error) {
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.datetime, e.location
+		SELECT ` + eventColumns + `
 		FROM events e
 		JOIN attendees a ON e.id = a.event_id
-		WHERE a.user_id = $1
+		WHERE a.user_id = $2 AND e.organization_id = $3 AND a.deleted_at IS NULL AND e.deleted_at IS NULL
+			AND ` + visibleTo + `
 	`
+	return queryEvents(m.DB, query, viewerId, attendeeId, orgId)
+}
+
+// ✅ GetDeleted — the event's trash of cancelled and removed registrations
+func (m *AttendeeModel) GetDeleted(eventId int) ([]*DeletedAttendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, attendeeId)
+	query := `
+		SELECT a.id, a.user_id, u.name, u.email, a.status,
+			(SELECT COUNT(*) FROM attendee_guests g WHERE g.attendee_id = a.id),
+			a.order_id, a.deleted_at
+		FROM attendees a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.event_id = $1 AND a.deleted_at IS NOT NULL
+		ORDER BY a.deleted_at DESC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var events []*Event
+	attendees := make([]*DeletedAttendee, 0)
 	for rows.Next() {
-		var event Event
-		if err := rows.Scan(
-			&event.Id,
-			&event.OwnerId,
-			&event.Name,
-			&event.Description,
-			&event.DateTime,
-			&event.Location,
-		); err != nil {
+		var a DeletedAttendee
+		if err := rows.Scan(&a.Id, &a.UserId, &a.Name, &a.Email, &a.Status, &a.GuestCount, &a.OrderId, &a.DeletedAt); err != nil {
 			return nil, err
 		}
-		events = append(events, &event)
+		attendees = append(attendees, &a)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	return attendees, nil
+}
+
+// ✅ Restore — takes a registration back out of the trash if its seats are
+// still free. Registrations whose order was refunded, that were handed to
+// someone else, or whose user is deleted stay put with ErrNotRestorable.
+// Returns nil if there is no such registration in the trash.
+func (m *AttendeeModel) Restore(eventId, id int) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	return events, nil
+	// A transfer deletes the old row in the same transaction that logs it
+	var restorable bool
+	var guests int
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM ticket_history h
+				WHERE h.event_id = a.event_id AND h.from_user_id = a.user_id
+					AND h.action = 'transferred' AND h.created_at = a.deleted_at
+			)
+			AND (a.order_id IS NULL OR EXISTS (
+				SELECT 1 FROM orders o
+				WHERE o.id = a.order_id AND o.status = 'paid'
+					AND NOT EXISTS (SELECT 1 FROM attendees a2 WHERE a2.order_id = o.id AND a2.deleted_at IS NULL)
+			)),
+			(SELECT COUNT(*) FROM attendee_guests g WHERE g.attendee_id = a.id)
+		FROM attendees a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.id = $1 AND a.event_id = $2 AND a.deleted_at IS NOT NULL
+		FOR UPDATE OF a
+	`, id, eventId).Scan(&restorable, &guests)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !restorable {
+		return nil, ErrNotRestorable
+	}
+
+	if err := reserveSeats(ctx, tx, eventId, 1+guests); err != nil {
+		return nil, err
+	}
+	var attendee Attendee
+	err = tx.QueryRowContext(ctx, `
+		UPDATE attendees SET deleted_at = NULL
+		WHERE id = $1
+		RETURNING id, event_id, user_id, status, status_message, checked_in_at, order_id
+	`, id).Scan(
+		&attendee.Id,
+		&attendee.EventId,
+		&attendee.UserId,
+		&attendee.Status,
+		&attendee.StatusMessage,
+		&attendee.CheckedInAt,
+		&attendee.OrderId,
+	)
+	if err != nil {
+		// The user has signed up again since
+		if err = translateError(err); errors.Is(err, ErrDuplicate) {
+			return nil, ErrAlreadyRegistered
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+// ✅ Purge — permanently removes registrations deleted before the cutoff
+func (m *AttendeeModel) Purge(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM attendees WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
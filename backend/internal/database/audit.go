@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	AuditEntityEvent    = "event"
+	AuditEntityAttendee = "attendee"
+)
+
+const (
+	AuditActionCreate            = "create"
+	AuditActionUpdate            = "update"
+	AuditActionDelete            = "delete"
+	AuditActionRestore           = "restore"
+	AuditActionStatusChange      = "status_change"
+	AuditActionOwnershipTransfer = "ownership_transfer"
+	AuditActionCheckIn           = "check_in"
+	AuditActionTransfer          = "transfer"
+)
+
+type AuditModel struct {
+	DB *sql.DB
+}
+
+// FieldChange is one field's value before and after a change. From is nil
+// for creations and To for deletions.
+type FieldChange struct {
+	From interface{} `json:"From"`
+	To   interface{} `json:"To"`
+}
+
+// AuditEntry records one change to an event or registration. Attendee
+// entries use the user's id as EntityId since transfers and re-registrations
+// create new attendee rows.
+type AuditEntry struct {
+	Id             int64                  `json:"Id"`
+	OrganizationId int                    `json:"OrganizationId"`
+	EventId        *int                   `json:"EventId,omitempty"`
+	EntityType     string                 `json:"EntityType"`
+	EntityId       int                    `json:"EntityId"`
+	Action         string                 `json:"Action"`
+	ActorId        *int                   `json:"ActorId,omitempty"`
+	RequestId      string                 `json:"RequestId,omitempty"`
+	Changes        map[string]FieldChange `json:"Changes"`
+	CreatedAt      time.Time              `json:"CreatedAt"`
+}
+
+// AuditFilter narrows an organization's audit log. Zero values match everything.
+type AuditFilter struct {
+	EventId    *int
+	EntityType string
+	Action     string
+	ActorId    *int
+	Since      *time.Time
+	Until      *time.Time
+	// Only entries older than this id, for paging backwards
+	BeforeId int64
+	Limit    int
+}
+
+// Diff lists the fields that differ between two values of the same struct
+// type, keyed by their JSON names. Either side may be nil to record a
+// creation or deletion in full.
+func Diff(before, after interface{}) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	if b.Kind() == reflect.Ptr {
+		b = b.Elem()
+	}
+	if a.Kind() == reflect.Ptr {
+		a = a.Elem()
+	}
+	var t reflect.Type
+	switch {
+	case a.IsValid():
+		t = a.Type()
+	case b.IsValid():
+		t = b.Type()
+	default:
+		return changes
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		from, to := auditValue(b, i), auditValue(a, i)
+		if fromTime, ok := from.(time.Time); ok {
+			// The same instant read back from the database may differ in location
+			if toTime, ok := to.(time.Time); ok && fromTime.Equal(toTime) {
+				continue
+			}
+		}
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		changes[name] = FieldChange{From: from, To: to}
+	}
+	return changes
+}
+
+// auditValue is the i-th field of v with pointers followed, or nil when
+// either v or the pointer is unset
+func auditValue(v reflect.Value, i int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	f := v.Field(i)
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	return f.Interface()
+}
+
+// ✅ Insert — appends an entry to the audit log
+func (m *AuditModel) Insert(entry *AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if entry.Changes == nil {
+		entry.Changes = map[string]FieldChange{}
+	}
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (organization_id, event_id, entity_type, entity_id, action, actor_id, request_id, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	return m.DB.QueryRowContext(ctx, query,
+		entry.OrganizationId,
+		entry.EventId,
+		entry.EntityType,
+		entry.EntityId,
+		entry.Action,
+		entry.ActorId,
+		entry.RequestId,
+		changes,
+	).Scan(&entry.Id, &entry.CreatedAt)
+}
+
+// ✅ GetByEvent — an event's own changes and those of its registrations, newest first
+func (m *AuditModel) GetByEvent(eventId int) ([]*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE event_id = $1 ORDER BY id DESC`
+	return m.queryEntries(ctx, query, eventId)
+}
+
+// ✅ Query — an organization's audit log, newest first
+func (m *AuditModel) Query(orgId int, filter AuditFilter) ([]*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_log
+		WHERE organization_id = $1
+			AND ($2::int IS NULL OR event_id = $2)
+			AND ($3 = '' OR entity_type = $3)
+			AND ($4 = '' OR action = $4)
+			AND ($5::int IS NULL OR actor_id = $5)
+			AND ($6::timestamp IS NULL OR created_at >= $6)
+			AND ($7::timestamp IS NULL OR created_at < $7)
+			AND ($8 = 0 OR id < $8)
+		ORDER BY id DESC
+		LIMIT $9
+	`
+	return m.queryEntries(ctx, query,
+		orgId,
+		filter.EventId,
+		filter.EntityType,
+		filter.Action,
+		filter.ActorId,
+		filter.Since,
+		filter.Until,
+		filter.BeforeId,
+		filter.Limit,
+	)
+}
+
+const auditColumns = `id, organization_id, event_id, entity_type, entity_id, action, actor_id, request_id, changes, created_at`
+
+func (m *AuditModel) queryEntries(ctx context.Context, query string, args ...interface{}) ([]*AuditEntry, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+	for rows.Next() {
+		var e AuditEntry
+		var changes []byte
+		err := rows.Scan(
+			&e.Id,
+			&e.OrganizationId,
+			&e.EventId,
+			&e.EntityType,
+			&e.EntityId,
+			&e.Action,
+			&e.ActorId,
+			&e.RequestId,
+			&changes,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
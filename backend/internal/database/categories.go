@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type CategoryModel struct {
+	DB *sql.DB
+}
+
+// Category is one entry of an organization's event taxonomy
+type Category struct {
+	Id             int    `json:"Id"`
+	OrganizationId int    `json:"OrganizationId"`
+	Name           string `json:"Name"`
+	Slug           string `json:"Slug"`
+	Description    string `json:"Description"`
+	// Lower comes first; ties are listed by name
+	Position int `json:"Position"`
+	// Events in the category, filled in when listing for browsing
+	EventCount int `json:"EventCount"`
+}
+
+// ✅ Insert — adds a category to the organization's taxonomy
+func (m *CategoryModel) Insert(category *Category) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_categories (organization_id, name, slug, description, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := m.DB.QueryRowContext(ctx, query,
+		category.OrganizationId,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.Position,
+	).Scan(&category.Id)
+	return translateError(err)
+}
+
+// ✅ GetByOrganization — the organization's categories in browsing order,
+// each with the number of events in the given states the viewer may see.
+// all counts every event, for organization admins.
+func (m *CategoryModel) GetByOrganization(orgId, viewerId int, statuses []string, all bool) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT c.id, c.organization_id, c.name, c.slug, c.description, c.position, COUNT(e.id)
+		FROM event_categories c
+		LEFT JOIN events e ON e.category_id = c.id AND e.deleted_at IS NULL
+			AND ($4::boolean OR ` + visibleTo + `)
+			AND (COALESCE(cardinality($3::text[]), 0) = 0 OR e.status = ANY($3))
+		WHERE c.organization_id = $2
+		GROUP BY c.id
+		ORDER BY c.position, c.name
+	`
+	rows, err := m.DB.QueryContext(ctx, query, viewerId, orgId, pq.Array(statuses), all)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]*Category, 0)
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.Id, &c.OrganizationId, &c.Name, &c.Slug, &c.Description, &c.Position, &c.EventCount); err != nil {
+			return nil, err
+		}
+		categories = append(categories, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// ✅ Get — one category of the organization, without its event count
+func (m *CategoryModel) Get(orgId, id int) (*Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, organization_id, name, slug, description, position
+		FROM event_categories
+		WHERE id = $1 AND organization_id = $2
+	`
+	var c Category
+	err := m.DB.QueryRowContext(ctx, query, id, orgId).
+		Scan(&c.Id, &c.OrganizationId, &c.Name, &c.Slug, &c.Description, &c.Position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ✅ Update — renames or reorders a category. Filters by the old slug stop
+// matching.
+func (m *CategoryModel) Update(category *Category) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE event_categories
+		SET name = $1, slug = $2, description = $3, position = $4
+		WHERE id = $5 AND organization_id = $6
+	`
+	_, err := m.DB.ExecContext(ctx, query,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.Position,
+		category.Id,
+		category.OrganizationId,
+	)
+	return translateError(err)
+}
+
+// ✅ Delete — removes a category; its events become uncategorized
+func (m *CategoryModel) Delete(orgId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM event_categories WHERE id = $1 AND organization_id = $2`, id, orgId)
+	return err
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ScanResultCheckedIn = "checked_in"
+	ScanResultDuplicate = "duplicate"
+	ScanResultInvalid   = "invalid"
+)
+
+// CheckInStats summarises door activity for an event
+type CheckInStats struct {
+	EventId         int `json:"EventId"`
+	Registered      int `json:"Registered"`
+	CheckedIn       int `json:"CheckedIn"`
+	ExpectedGuests  int `json:"ExpectedGuests"`
+	CheckedInGuests int `json:"CheckedInGuests"`
+	DuplicateScans  int `json:"DuplicateScans"`
+	InvalidScans    int `json:"InvalidScans"`
+}
+
+// CheckInResult describes the attendee a ticket was scanned for
+type CheckInResult struct {
+	AttendeeId  int       `json:"AttendeeId"`
+	UserId      int       `json:"UserId"`
+	Name        string    `json:"Name"`
+	GuestCount  int       `json:"GuestCount"`
+	Status      string    `json:"Status"`
+	CheckedInAt time.Time `json:"CheckedInAt"`
+	// Set when the ticket had already been used
+	Duplicate bool `json:"Duplicate"`
+}
+
+// ✅ CheckIn — stamps the attendee as arrived. A second scan does not move the
+// timestamp; it comes back flagged as a duplicate with the original time.
+// Returns nil when the attendee does not belong to the event.
+func (m *AttendeeModel) CheckIn(eventId, attendeeId, staffId int) (*CheckInResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT a.id, u.id, u.name, a.status, a.checked_in_at,
+			(SELECT COUNT(*) FROM attendee_guests g WHERE g.attendee_id = a.id)
+		FROM attendees a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.id = $1 AND a.event_id = $2 AND a.deleted_at IS NULL
+		FOR UPDATE OF a
+	`
+	var result CheckInResult
+	var checkedInAt sql.NullTime
+	err = tx.QueryRowContext(ctx, query, attendeeId, eventId).Scan(
+		&result.AttendeeId,
+		&result.UserId,
+		&result.Name,
+		&result.Status,
+		&checkedInAt,
+		&result.GuestCount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	scan := ScanResultCheckedIn
+	switch {
+	case result.Status != AttendeeStatusConfirmed:
+		scan = ScanResultInvalid
+	case checkedInAt.Valid:
+		scan = ScanResultDuplicate
+		result.Duplicate = true
+		result.CheckedInAt = checkedInAt.Time
+	default:
+		err = tx.QueryRowContext(ctx,
+			`UPDATE attendees SET checked_in_at = CURRENT_TIMESTAMP, checked_in_by = $1 WHERE id = $2 RETURNING checked_in_at`,
+			staffId, attendeeId,
+		).Scan(&result.CheckedInAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordScan(ctx, tx, eventId, &attendeeId, staffId, scan); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ✅ RecordInvalidScan — logs a scan whose code could not be verified
+func (m *AttendeeModel) RecordInvalidScan(eventId, staffId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordScan(ctx, tx, eventId, nil, staffId, ScanResultInvalid); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func recordScan(ctx context.Context, tx *sql.Tx, eventId int, attendeeId *int, staffId int, result string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO check_in_scans (event_id, attendee_id, scanned_by, result) VALUES ($1, $2, $3, $4)`,
+		eventId, attendeeId, staffId, result,
+	)
+	return err
+}
+
+// ✅ GetCheckInStats — registered vs arrived counts, for the door dashboard
+func (m *AttendeeModel) GetCheckInStats(eventId int) (*CheckInStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(a.checked_in_at),
+			COALESCE(SUM(g.guests), 0),
+			COALESCE(SUM(g.guests) FILTER (WHERE a.checked_in_at IS NOT NULL), 0),
+			(SELECT COUNT(*) FROM check_in_scans s WHERE s.event_id = $1 AND s.result = 'duplicate'),
+			(SELECT COUNT(*) FROM check_in_scans s WHERE s.event_id = $1 AND s.result = 'invalid')
+		FROM attendees a
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS guests FROM attendee_guests WHERE attendee_id = a.id
+		) g ON TRUE
+		WHERE a.event_id = $1 AND a.status = 'confirmed' AND a.deleted_at IS NULL
+	`
+
+	stats := CheckInStats{EventId: eventId}
+	err := m.DB.QueryRowContext(ctx, query, eventId).Scan(
+		&stats.Registered,
+		&stats.CheckedIn,
+		&stats.ExpectedGuests,
+		&stats.CheckedInGuests,
+		&stats.DuplicateScans,
+		&stats.InvalidScans,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrDuplicate is matched by writes that break a unique constraint
+	ErrDuplicate = errors.New("value is already in use")
+	// ErrInvalidReference is matched by writes that point at a missing row, or
+	// deletes of a row something still points at
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	// ErrCheckViolation is matched by writes a CHECK constraint rejects
+	ErrCheckViolation = errors.New("value is not allowed")
+	// ErrMissingValue is matched by writes that leave a NOT NULL column empty
+	ErrMissingValue = errors.New("value is required")
+	// ErrOverlap is matched by writes an exclusion constraint rejects
+	ErrOverlap = errors.New("value overlaps an existing record")
+)
+
+// Postgres error codes of the integrity constraint violation class
+var constraintKinds = map[pq.ErrorCode]error{
+	"23505": ErrDuplicate,
+	"23503": ErrInvalidReference,
+	"23514": ErrCheckViolation,
+	"23502": ErrMissingValue,
+	"23P01": ErrOverlap,
+}
+
+// ConstraintError is Postgres refusing a write because of a constraint. It
+// matches one of the sentinel errors above with errors.Is.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+	// Columns the constraint covers, as far as Postgres reports them
+	Columns []string
+	Err     *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	if len(e.Columns) > 0 {
+		return e.Table + "." + strings.Join(e.Columns, ",") + ": " + e.Kind.Error()
+	}
+	return e.Table + ": " + e.Kind.Error()
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// "Key (user_id, event_id)=(1, 2) already exists." and the like
+var constraintKeyDetail = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// translateError turns constraint violations into *ConstraintError so callers
+// need not know Postgres error codes. Anything else is returned unchanged.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	kind, ok := constraintKinds[pqErr.Code]
+	if !ok {
+		return err
+	}
+
+	e := &ConstraintError{Kind: kind, Table: pqErr.Table, Constraint: pqErr.Constraint, Err: pqErr}
+	switch {
+	case pqErr.Column != "":
+		e.Columns = []string{pqErr.Column}
+	case kind == ErrOverlap:
+		// Exclusion keys are expressions, not columns
+	case constraintKeyDetail.MatchString(pqErr.Detail):
+		for _, column := range strings.Split(constraintKeyDetail.FindStringSubmatch(pqErr.Detail)[1], ",") {
+			e.Columns = append(e.Columns, strings.TrimSpace(column))
+		}
+	case kind == ErrCheckViolation:
+		// Postgres names column checks <table>_<column>_check
+		prefix, suffix := pqErr.Table+"_", "_check"
+		name := pqErr.Constraint
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) && len(name) > len(prefix)+len(suffix) {
+			e.Columns = []string{name[len(prefix) : len(name)-len(suffix)]}
+		}
+	}
+	return e
+}
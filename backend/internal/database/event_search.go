@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// EventSearch describes a search over an organization's events. Zero values
+// of the filters match everything.
+type EventSearch struct {
+	Query string
+	From  *time.Time
+	Until *time.Time
+	// Part of the location, e.g. a city
+	Location string
+	Statuses []string
+	// Search everything regardless of visibility, for organization admins
+	All    bool
+	Limit  int
+	Offset int
+}
+
+// EventSearchResult is an event that matched a search, with its score and
+// the matching words wrapped in <mark> tags
+type EventSearchResult struct {
+	Event
+	Rank       float64         `json:"Rank"`
+	Highlights EventHighlights `json:"Highlights"`
+}
+
+// EventHighlights holds HTML-escaped copies of the searched fields. The
+// description is cut down to the fragments around the matches.
+type EventHighlights struct {
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	Location    string `json:"Location"`
+}
+
+// PrefixQuery turns free text into a tsquery matching every word as a
+// prefix, so "jazz fest" finds "Jazz Festival". It returns "" when the text
+// has no words.
+func PrefixQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>`
+
+// highlight escapes a ts_headline result for HTML while keeping its <mark> tags
+func highlight(s string) string {
+	s = html.EscapeString(s)
+	return strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").Replace(s)
+}
+
+// ✅ Search — ranks the organization's events against free text. Words match
+// by prefix through the full-text index, and names or locations within a
+// typo or two match through trigram similarity. viewerId limits results to
+// what that user may see unless search.All is set.
+func (m *EventModel) Search(orgId, viewerId int, search EventSearch) ([]*EventSearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		WITH search AS (
+			SELECT to_tsquery('english', $3) AS query, $4::text AS text
+		)
+		SELECT ` + eventColumns + `,
+			ts_rank(e.search_vector, s.query)
+				+ GREATEST(word_similarity(s.text, e.name), word_similarity(s.text, e.location)) / 2 AS rank,
+			ts_headline('english', e.name, s.query, 'HighlightAll=true, ` + headlineOptions + `'),
+			ts_headline('english', e.description, s.query, 'MaxFragments=2, MaxWords=30, MinWords=10, ` + headlineOptions + `'),
+			ts_headline('english', e.location, s.query, 'HighlightAll=true, ` + headlineOptions + `')
+		FROM events e, search s
+		WHERE e.organization_id = $2 AND e.deleted_at IS NULL
+			AND ($5::boolean OR ` + visibleTo + `)
+			AND (e.search_vector @@ s.query OR s.text <% e.name OR s.text <% e.location)
+			AND ($6::timestamp IS NULL OR e.datetime >= $6)
+			AND ($7::timestamp IS NULL OR e.datetime < $7)
+			AND ($8 = '' OR strpos(lower(e.location), lower($8)) > 0 OR $8 <% e.location)
+			AND (COALESCE(cardinality($9::text[]), 0) = 0 OR e.status = ANY($9))
+		ORDER BY rank DESC, e.datetime
+		LIMIT $10 OFFSET $11
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query,
+		viewerId,
+		orgId,
+		PrefixQuery(search.Query),
+		search.Query,
+		search.All,
+		search.From,
+		search.Until,
+		search.Location,
+		pq.Array(search.Statuses),
+		search.Limit,
+		search.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]*EventSearchResult, 0)
+	for rows.Next() {
+		var r EventSearchResult
+		event, err := scanEvent(rows, &r.Rank, &r.Highlights.Name, &r.Highlights.Description, &r.Highlights.Location)
+		if err != nil {
+			return nil, err
+		}
+		r.Event = *event
+		r.Highlights.Name = highlight(r.Highlights.Name)
+		r.Highlights.Description = highlight(r.Highlights.Description)
+		r.Highlights.Location = highlight(r.Highlights.Location)
+		results = append(results, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// NearbySearch finds events around a point
+type NearbySearch struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	EventFilter
+	// Search everything regardless of visibility, for organization admins
+	All bool
+}
+
+// Kilometres per degree of latitude, for the bounding band
+const kmPerDegree = 111.2
+
+// ✅ GetNear — the organization's events within near.RadiusKm of a point,
+// nearest first. Events without coordinates are left out. viewerId limits
+// results to what that user may see unless near.All is set.
+func (m *EventModel) GetNear(orgId, viewerId int, near NearbySearch) ([]*EventNearby, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Great-circle distance by the haversine formula on a 6371 km sphere
+	query := `
+		SELECT ` + eventColumns + `, d.km
+		FROM events e
+		CROSS JOIN LATERAL (
+			SELECT 2 * 6371 * asin(sqrt(LEAST(1,
+				power(sin(radians(e.latitude - $3) / 2), 2)
+				+ cos(radians($3)) * cos(radians(e.latitude)) * power(sin(radians(e.longitude - $4) / 2), 2)
+			))) AS km
+		) d
+		WHERE e.organization_id = $2 AND e.deleted_at IS NULL
+			AND ($6::boolean OR ` + visibleTo + `)
+			AND e.latitude BETWEEN $3 - $5::float8 / $7 AND $3 + $5::float8 / $7
+			AND d.km <= $5
+			AND ` + near.filterSQL(8) + `
+		ORDER BY d.km, e.datetime
+	`
+
+	args := []interface{}{
+		viewerId,
+		orgId,
+		near.Latitude,
+		near.Longitude,
+		near.RadiusKm,
+		near.All,
+		kmPerDegree,
+	}
+	rows, err := m.DB.QueryContext(ctx, query, append(args, near.args()...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*EventNearby, 0)
+	for rows.Next() {
+		var e EventNearby
+		event, err := scanEvent(rows, &e.DistanceKm)
+		if err != nil {
+			return nil, err
+		}
+		e.Event = *event
+		events = append(events, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// TagCount is a tag with the number of events carrying it
+type TagCount struct {
+	Tag   string `json:"Tag"`
+	Count int    `json:"Count"`
+}
+
+// ✅ SuggestTags — the organization's tags starting with prefix, most used
+// first, counting only events the viewer may see unless all is set
+func (m *EventModel) SuggestTags(orgId, viewerId int, prefix string, all bool, limit int) ([]*TagCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Escape LIKE wildcards so the prefix matches literally
+	prefix = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+	query := `
+		SELECT t.tag, COUNT(*)
+		FROM events e
+		CROSS JOIN LATERAL unnest(e.tags) AS t(tag)
+		WHERE e.organization_id = $2 AND e.deleted_at IS NULL
+			AND ($3::boolean OR ` + visibleTo + `)
+			AND t.tag LIKE $4 || '%'
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag
+		LIMIT $5
+	`
+	rows, err := m.DB.QueryContext(ctx, query, viewerId, orgId, all, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]*TagCount, 0)
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.Tag, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
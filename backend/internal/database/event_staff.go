@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	StaffRoleCoOrganizer     = "co_organizer"
+	StaffRoleAttendeeManager = "attendee_manager"
+	StaffRoleCheckIn         = "checkin"
+)
+
+type Permission string
+
+const (
+	PermissionEditDetails     Permission = "edit_details"
+	PermissionManageAttendees Permission = "manage_attendees"
+	PermissionCheckIn         Permission = "check_in"
+	PermissionManageStaff     Permission = "manage_staff"
+	PermissionDelete          Permission = "delete"
+)
+
+// AllPermissions is what the event owner and organization admins hold
+var AllPermissions = []Permission{
+	PermissionEditDetails,
+	PermissionManageAttendees,
+	PermissionCheckIn,
+	PermissionManageStaff,
+	PermissionDelete,
+}
+
+// StaffRolePermissions maps each staff role to what it may do on the event
+var StaffRolePermissions = map[string][]Permission{
+	StaffRoleCoOrganizer:     {PermissionEditDetails, PermissionManageAttendees, PermissionCheckIn},
+	StaffRoleAttendeeManager: {PermissionManageAttendees, PermissionCheckIn},
+	StaffRoleCheckIn:         {PermissionCheckIn},
+}
+
+type EventStaffModel struct {
+	DB *sql.DB
+}
+
+type EventStaff struct {
+	EventId int    `json:"EventId"`
+	UserId  int    `json:"UserId"`
+	Name    string `json:"Name"`
+	// Left out for staff who only check people in
+	Email string `json:"Email,omitempty"`
+	Role  string `json:"Role"`
+}
+
+// ✅ GetRole — the user's staff role on the event, or "" if none
+func (m *EventStaffModel) GetRole(eventId, userId int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT role FROM event_staff WHERE event_id = $1 AND user_id = $2`
+
+	var role string
+	err := m.DB.QueryRowContext(ctx, query, eventId, userId).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// ✅ GetByEvent — everyone on the event's staff
+func (m *EventStaffModel) GetByEvent(eventId int) ([]*EventStaff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT s.event_id, u.id, u.name, u.email, s.role
+		FROM event_staff s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.event_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := make([]*EventStaff, 0)
+	for rows.Next() {
+		var member EventStaff
+		if err := rows.Scan(&member.EventId, &member.UserId, &member.Name, &member.Email, &member.Role); err != nil {
+			return nil, err
+		}
+		staff = append(staff, &member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// ✅ Set — assigns or changes a staff role
+func (m *EventStaffModel) Set(eventId, userId int, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_staff (event_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := m.DB.ExecContext(ctx, query, eventId, userId, role)
+	return translateError(err)
+}
+
+// ✅ Remove — takes a user off the event's staff
+func (m *EventStaffModel) Remove(eventId, userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM event_staff WHERE event_id = $1 AND user_id = $2`
+	_, err := m.DB.ExecContext(ctx, query, eventId, userId)
+	return err
+}
+
+// ✅ TransferOwnership — hands the event to another user; the previous owner
+// stays on as co-organizer so they do not lose access. event.Version must be
+// the version the transfer was based on; ErrEditConflict means someone else
+// wrote first. The event's owner and version are updated on success.
+func (m *EventStaffModel) TransferOwnership(event *Event, toUserId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromUserId := event.OwnerId
+	var version int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE events
+		SET owner_id = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL AND version = $3
+		RETURNING version
+	`, toUserId, event.Id, event.Version).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrEditConflict
+		}
+		return translateError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_staff WHERE event_id = $1 AND user_id = $2`, event.Id, toUserId); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO event_staff (event_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	if _, err := tx.ExecContext(ctx, query, event.Id, fromUserId, StaffRoleCoOrganizer); err != nil {
+		return translateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	event.OwnerId = toUserId
+	event.Version = version
+	return nil
+}
@@ -3,7 +3,11 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type EventModel struct {
@@ -11,48 +15,214 @@ type EventModel struct {
 }
 
 type Event struct {
-	Id          int    `json:"Id"`
-	OwnerId     int    `json:"UserId" binding:"required"`
-	Name        string `json:"Name" binding:"required,min=3"`
-	Description string `json:"Description" binding:"required,min=10"`
-	DateTime    string `json:"DateTime" binding:"required"` 
-	Location    string `json:"Location" binding:"required,min=3"`
+	Id             int    `json:"Id"`
+	OrganizationId int    `json:"OrganizationId"`
+	OwnerId        int    `json:"UserId" binding:"required"`
+	Name           string `json:"Name" binding:"required,min=3"`
+	Description    string `json:"Description" binding:"required,min=10"`
+	DateTime       string `json:"DateTime" binding:"required"`
+	Location       string `json:"Location" binding:"required,min=3"`
+	Visibility     string `json:"Visibility"`
+	// New registrations wait for an organizer's approval
+	RequiresApproval bool `json:"RequiresApproval"`
+	// Seats including guests; nil means unlimited
+	Capacity  *int `json:"Capacity"`
+	MaxGuests int  `json:"MaxGuests"`
+	// Share of the ticket price returned when an attendee cancels, up to
+	// RefundCutoffHours before the event starts
+	RefundPercent     int `json:"RefundPercent"`
+	RefundCutoffHours int `json:"RefundCutoffHours"`
+
+	// Where the venue is; nil until given or geocoded from the location
+	Latitude  *float64 `json:"Latitude"`
+	Longitude *float64 `json:"Longitude"`
+	Address   *Address `json:"Address,omitempty"`
+	// A saved venue, and optionally the room booked in it
+	VenueId *int `json:"VenueId,omitempty"`
+	RoomId  *int `json:"RoomId,omitempty"`
+	// When the event is over; room bookings assume DefaultEventDuration without one
+	EndsAt *time.Time `json:"EndsAt,omitempty"`
+
+	// One of the organization's categories
+	CategoryId *int `json:"CategoryId"`
+	// Free-form labels, normalized to lowercase words joined by hyphens
+	Tags []string `json:"Tags"`
+
+	Status          string     `json:"Status"`
+	StatusReason    string     `json:"StatusReason,omitempty"`
+	StatusChangedAt *time.Time `json:"StatusChangedAt,omitempty"`
+	// When a draft goes live on its own; nil means it waits for a manual publish
+	PublishAt *time.Time `json:"PublishAt,omitempty"`
+	// Set while the event sits in the trash
+	DeletedAt *time.Time `json:"DeletedAt,omitempty"`
+	DeletedBy *int       `json:"DeletedBy,omitempty"`
+	// Incremented by every write; the event's ETag
+	Version int `json:"Version"`
 }
 
-// ✅ Insert — PostgreSQL-compatible (uses $1, $2, ... + RETURNING id)
-func (m *EventModel) Insert(event *Event) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+// Address is the optional structured form of an event's location
+type Address struct {
+	Street     string `json:"Street" binding:"max=255"`
+	City       string `json:"City" binding:"max=100"`
+	Region     string `json:"Region" binding:"max=100"`
+	PostalCode string `json:"PostalCode" binding:"max=20"`
+	Country    string `json:"Country" binding:"max=100"`
+}
 
-	query := `
-		INSERT INTO events (owner_id, name, description, datetime, location)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+// EventFilter narrows an event listing. Zero values match everything.
+type EventFilter struct {
+	Statuses []string
+	// Slug of one of the organization's categories
+	Category string
+	// Events must carry every one of these
+	Tags []string
+}
 
-	err := m.DB.QueryRowContext(ctx, query,
-		event.OwnerId,
-		event.Name,
-		event.Description,
-		event.DateTime,
-		event.Location,
-	).Scan(&event.Id)
+// filterSQL matches events (aliased e) against an EventFilter bound by args
+// from $n on
+func (f EventFilter) filterSQL(n int) string {
+	return fmt.Sprintf(`(COALESCE(cardinality($%[1]d::text[]), 0) = 0 OR e.status = ANY($%[1]d))
+			AND ($%[2]d = '' OR e.category_id = (
+				SELECT ec.id FROM event_categories ec WHERE ec.organization_id = e.organization_id AND ec.slug = $%[2]d
+			))
+			AND e.tags @> COALESCE($%[3]d::text[], '{}')`, n, n+1, n+2)
+}
 
-	if err != nil {
-		return err
+func (f EventFilter) args() []interface{} {
+	return []interface{}{pq.Array(f.Statuses), f.Category, pq.Array(f.Tags)}
+}
+
+// EventNearby is an event with its distance from a searched point
+type EventNearby struct {
+	Event
+	DistanceKm float64 `json:"DistanceKm"`
+}
+
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+const (
+	EventStatusDraft     = "draft"
+	EventStatusPublished = "published"
+	EventStatusPostponed = "postponed"
+	EventStatusCancelled = "cancelled"
+	EventStatusCompleted = "completed"
+)
+
+var (
+	// ErrEventClosed is returned when registering for an event that is not taking sign-ups
+	ErrEventClosed = errors.New("event is not open for registration")
+	// ErrEditConflict is returned when the event changed underneath an update
+	ErrEditConflict = errors.New("event was modified concurrently")
+	// ErrRoomBooked is returned when another live event holds the room at that time
+	ErrRoomBooked = errors.New("room is booked at that time")
+)
+
+// translateEventError is translateError plus the events table's own constraints
+func translateEventError(err error) error {
+	err = translateError(err)
+	if errors.Is(err, ErrOverlap) {
+		return ErrRoomBooked
 	}
+	return err
+}
 
-	return nil
+// eventTransitions lists the states each state may move to. Cancelled and
+// completed events are final.
+var eventTransitions = map[string][]string{
+	EventStatusDraft:     {EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusPostponed, EventStatusCancelled, EventStatusCompleted},
+	EventStatusPostponed: {EventStatusPublished, EventStatusCancelled, EventStatusCompleted},
+}
+
+// ValidEventStatus reports whether s is a known lifecycle state
+func ValidEventStatus(s string) bool {
+	switch s {
+	case EventStatusDraft, EventStatusPublished, EventStatusPostponed, EventStatusCancelled, EventStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether the event may move to the given state
+func (e *Event) CanTransition(to string) bool {
+	for _, s := range eventTransitions[e.Status] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidVisibility reports whether v is a known visibility setting
+func ValidVisibility(v string) bool {
+	return v == VisibilityPublic || v == VisibilityUnlisted || v == VisibilityPrivate
 }
 
-// ✅ GetAll — fetches all events
-func (m *EventModel) GetAll() ([]*Event, error) {
+// eventColumns expects the events table to be aliased as e
+const eventColumns = `e.id, e.organization_id, e.owner_id, e.name, e.description, e.datetime, e.location, e.visibility, e.requires_approval,
+	e.capacity, e.max_guests, e.refund_percent, e.refund_cutoff_hours, e.status, e.status_reason, e.status_changed_at,
+	e.publish_at, e.deleted_at, e.deleted_by, e.version, e.latitude, e.longitude,
+	COALESCE(e.street, ''), COALESCE(e.city, ''), COALESCE(e.region, ''), COALESCE(e.postal_code, ''), COALESCE(e.country, ''),
+	e.venue_id, e.room_id, e.ends_at, e.category_id, e.tags`
+
+// scanEvent reads eventColumns, followed by any extra columns the query
+// selected into the given destinations
+func scanEvent(row rowScanner, extra ...interface{}) (*Event, error) {
+	var event Event
+	var address Address
+	dest := []interface{}{
+		&event.Id,
+		&event.OrganizationId,
+		&event.OwnerId,
+		&event.Name,
+		&event.Description,
+		&event.DateTime,
+		&event.Location,
+		&event.Visibility,
+		&event.RequiresApproval,
+		&event.Capacity,
+		&event.MaxGuests,
+		&event.RefundPercent,
+		&event.RefundCutoffHours,
+		&event.Status,
+		&event.StatusReason,
+		&event.StatusChangedAt,
+		&event.PublishAt,
+		&event.DeletedAt,
+		&event.DeletedBy,
+		&event.Version,
+		&event.Latitude,
+		&event.Longitude,
+		&address.Street,
+		&address.City,
+		&address.Region,
+		&address.PostalCode,
+		&address.Country,
+		&event.VenueId,
+		&event.RoomId,
+		&event.EndsAt,
+		&event.CategoryId,
+		pq.Array(&event.Tags),
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if address != (Address{}) {
+		event.Address = &address
+	}
+	return &event, nil
+}
+
+// queryEvents runs a SELECT of eventColumns and collects the rows
+func queryEvents(db *sql.DB, query string, args ...interface{}) ([]*Event, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `SELECT id, owner_id, name, description, datetime, location FROM events ORDER BY datetime DESC`
-
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,19 +230,11 @@ func (m *EventModel) GetAll() ([]*Event, error) {
 
 	events := make([]*Event, 0) // Initialize empty slice instead of nil
 	for rows.Next() {
-		var event Event
-		err := rows.Scan(
-			&event.Id,
-			&event.OwnerId,
-			&event.Name,
-			&event.Description,
-			&event.DateTime,
-			&event.Location,
-		)
+		event, err := scanEvent(rows)
 		if err != nil {
 			return nil, err
 		}
-		events = append(events, &event)
+		events = append(events, event)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -81,26 +243,125 @@ func (m *EventModel) GetAll() ([]*Event, error) {
 	return events, nil
 }
 
-// ✅ Get — retrieves one event by ID (Postgres uses $1)
-func (m *EventModel) Get(id int) (*Event, error) {
+// ✅ Insert — PostgreSQL-compatible (uses $1, $2, ... + RETURNING id)
+func (m *EventModel) Insert(event *Event) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT id, owner_id, name, description, datetime, location
-		FROM events
-		WHERE id = $1
+		INSERT INTO events (organization_id, owner_id, name, description, datetime, location, visibility, requires_approval,
+			capacity, max_guests, refund_percent, refund_cutoff_hours, status, publish_at,
+			latitude, longitude, street, city, region, postal_code, country, venue_id, room_id, ends_at, category_id, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+			$15, $16, NULLIF($17, ''), NULLIF($18, ''), NULLIF($19, ''), NULLIF($20, ''), NULLIF($21, ''), $22, $23, $24,
+			$25, COALESCE($26::text[], '{}'))
+		RETURNING id, version
 	`
 
-	var event Event
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&event.Id,
-		&event.OwnerId,
-		&event.Name,
-		&event.Description,
-		&event.DateTime,
-		&event.Location,
-	)
+	if event.Visibility == "" {
+		event.Visibility = VisibilityPublic
+	}
+	if event.Status == "" {
+		event.Status = EventStatusPublished
+	}
+
+	address := event.Address
+	if address == nil {
+		address = &Address{}
+	}
+	err := m.DB.QueryRowContext(ctx, query,
+		event.OrganizationId,
+		event.OwnerId,
+		event.Name,
+		event.Description,
+		event.DateTime,
+		event.Location,
+		event.Visibility,
+		event.RequiresApproval,
+		event.Capacity,
+		event.MaxGuests,
+		event.RefundPercent,
+		event.RefundCutoffHours,
+		event.Status,
+		event.PublishAt,
+		event.Latitude,
+		event.Longitude,
+		address.Street,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		event.VenueId,
+		event.RoomId,
+		event.EndsAt,
+		event.CategoryId,
+		pq.Array(event.Tags),
+	).Scan(&event.Id, &event.Version)
+
+	if err != nil {
+		return translateEventError(err)
+	}
+
+	return nil
+}
+
+// ✅ GetAll — fetches all events of one organization, optionally narrowed by
+// state, category and tags
+func (m *EventModel) GetAll(orgId int, filter EventFilter) ([]*Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.organization_id = $1 AND e.deleted_at IS NULL
+			AND ` + filter.filterSQL(2) + `
+		ORDER BY e.datetime DESC
+	`
+	return queryEvents(m.DB, query, append([]interface{}{orgId}, filter.args()...)...)
+}
+
+// visibleTo restricts a query to events the viewer may see: published public
+// ones and those they are confirmed for, plus anything they own or staff,
+// drafts included.
+// $1 is the viewer's user ID (0 for anonymous).
+const visibleTo = `(
+	(e.status <> 'draft' AND (
+		e.visibility = 'public'
+		OR EXISTS (SELECT 1 FROM attendees a2 WHERE a2.event_id = e.id AND a2.user_id = $1 AND a2.status = 'confirmed' AND a2.deleted_at IS NULL)
+	))
+	OR e.owner_id = $1
+	OR EXISTS (SELECT 1 FROM event_staff s2 WHERE s2.event_id = e.id AND s2.user_id = $1)
+)`
+
+// ✅ GetAllVisible — lists the organization's events as seen by one viewer,
+// optionally narrowed by state, category and tags
+func (m *EventModel) GetAllVisible(orgId, viewerId int, filter EventFilter) ([]*Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.organization_id = $2 AND e.deleted_at IS NULL AND ` + visibleTo + `
+			AND ` + filter.filterSQL(3) + `
+		ORDER BY e.datetime DESC
+	`
+	return queryEvents(m.DB, query, append([]interface{}{viewerId, orgId}, filter.args()...)...)
+}
+
+// ✅ GetByOwner — events created by a single user, across all organizations
+func (m *EventModel) GetByOwner(ownerId int) ([]*Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events e WHERE e.owner_id = $1 AND e.deleted_at IS NULL ORDER BY e.datetime DESC`
+	return queryEvents(m.DB, query, ownerId)
+}
+
+// ✅ Get — retrieves one event by ID within an organization
+func (m *EventModel) Get(orgId, id int) (*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.id = $1 AND e.organization_id = $2 AND e.deleted_at IS NULL
+	`
+
+	event, err := scanEvent(m.DB.QueryRowContext(ctx, query, id, orgId))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // event not found
@@ -108,36 +369,233 @@ func (m *EventModel) Get(id int) (*Event, error) {
 		return nil, err
 	}
 
-	return &event, nil
+	return event, nil
+}
+
+// ✅ GetById — retrieves an event regardless of organization. Only for flows
+// that carry their own authorization, such as invitation tokens.
+func (m *EventModel) GetById(id int) (*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + eventColumns + ` FROM events e WHERE e.id = $1 AND e.deleted_at IS NULL`
+
+	event, err := scanEvent(m.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return event, nil
 }
 
-// ✅ Update — PostgreSQL-compatible
+// ✅ Update — PostgreSQL-compatible. event.Version must be the version the
+// changes were based on; ErrEditConflict means someone else wrote first.
 func (m *EventModel) Update(event *Event) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
 		UPDATE events
-		SET name = $1, description = $2, datetime = $3, location = $4
-		WHERE id = $5
+		SET name = $1, description = $2, datetime = $3, location = $4, visibility = $5, requires_approval = $6,
+			capacity = $7, max_guests = $8, refund_percent = $9, refund_cutoff_hours = $10, publish_at = $11,
+			latitude = $15, longitude = $16, street = NULLIF($17, ''), city = NULLIF($18, ''), region = NULLIF($19, ''),
+			postal_code = NULLIF($20, ''), country = NULLIF($21, ''), venue_id = $22, room_id = $23, ends_at = $24,
+			category_id = $25, tags = COALESCE($26::text[], '{}'),
+			updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $12 AND organization_id = $13 AND deleted_at IS NULL AND version = $14
+		RETURNING version
 	`
 
-	_, err := m.DB.ExecContext(ctx, query,
+	address := event.Address
+	if address == nil {
+		address = &Address{}
+	}
+	err := m.DB.QueryRowContext(ctx, query,
 		event.Name,
 		event.Description,
 		event.DateTime,
 		event.Location,
+		event.Visibility,
+		event.RequiresApproval,
+		event.Capacity,
+		event.MaxGuests,
+		event.RefundPercent,
+		event.RefundCutoffHours,
+		event.PublishAt,
 		event.Id,
-	)
-	return err
+		event.OrganizationId,
+		event.Version,
+		event.Latitude,
+		event.Longitude,
+		address.Street,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		event.VenueId,
+		event.RoomId,
+		event.EndsAt,
+		event.CategoryId,
+		pq.Array(event.Tags),
+	).Scan(&event.Version)
+	if err == sql.ErrNoRows {
+		return ErrEditConflict
+	}
+	return translateEventError(err)
 }
 
-// ✅ Delete — PostgreSQL-compatible
-func (m *EventModel) Delete(id int) error {
+// ✅ SetStatus — moves the event to a new lifecycle state. dateTime, when not
+// empty, reschedules it at the same time.
+func (m *EventModel) SetStatus(event *Event, status, reason, dateTime string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := `DELETE FROM events WHERE id = $1`
-	_, err := m.DB.ExecContext(ctx, query, id)
-	return err
-}
\ No newline at end of file
+	if dateTime == "" {
+		dateTime = event.DateTime
+	}
+
+	query := `
+		UPDATE events
+		SET status = $1, status_reason = $2, status_changed_at = CURRENT_TIMESTAMP, publish_at = NULL,
+			-- A rescheduled event keeps its length
+			ends_at = ends_at + ($3::timestamp - datetime), datetime = $3,
+			updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $4 AND status = $5 AND deleted_at IS NULL
+		RETURNING status_changed_at, datetime, ends_at, version
+	`
+	err := m.DB.QueryRowContext(ctx, query, status, reason, dateTime, event.Id, event.Status).
+		Scan(&event.StatusChangedAt, &event.DateTime, &event.EndsAt, &event.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Someone else changed the state first
+			return ErrEditConflict
+		}
+		return translateEventError(err)
+	}
+	event.Status = status
+	event.StatusReason = reason
+	event.PublishAt = nil
+	return nil
+}
+
+// ✅ PublishDue — publishes drafts whose scheduled time has come and returns them
+func (m *EventModel) PublishDue() ([]*Event, error) {
+	query := `
+		UPDATE events e
+		SET status = 'published', status_changed_at = CURRENT_TIMESTAMP, publish_at = NULL, updated_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE e.status = 'draft' AND e.publish_at <= NOW() AND e.deleted_at IS NULL
+		RETURNING ` + eventColumns
+	return queryEvents(m.DB, query)
+}
+
+// ✅ RefundWindowOpen — whether attendees cancelling now are still owed a refund
+func (m *EventModel) RefundWindowOpen(id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT NOW() < datetime - make_interval(hours => refund_cutoff_hours) FROM events WHERE id = $1`
+
+	var open bool
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&open)
+	return open, err
+}
+
+// ✅ Delete — moves the event to the trash; it can be restored until purged.
+// Returns ErrEditConflict unless the event is still at the given version.
+func (m *EventModel) Delete(orgId, id, version, deletedBy int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE events
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $4, version = version + 1
+		WHERE id = $1 AND organization_id = $2 AND version = $3 AND deleted_at IS NULL
+	`
+	result, err := m.DB.ExecContext(ctx, query, id, orgId, version, deletedBy)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrEditConflict
+	}
+	return nil
+}
+
+// ✅ GetDeleted — the organization's trash, optionally only one owner's events
+func (m *EventModel) GetDeleted(orgId int, ownerId *int) ([]*Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.organization_id = $1 AND e.deleted_at IS NOT NULL AND ($2::int IS NULL OR e.owner_id = $2)
+		ORDER BY e.deleted_at DESC
+	`
+	return queryEvents(m.DB, query, orgId, ownerId)
+}
+
+// ✅ GetDeletedById — one event from the organization's trash
+func (m *EventModel) GetDeletedById(orgId, id int) (*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + eventColumns + ` FROM events e WHERE e.id = $1 AND e.organization_id = $2 AND e.deleted_at IS NOT NULL`
+
+	event, err := scanEvent(m.DB.QueryRowContext(ctx, query, id, orgId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// ✅ Restore — takes an event back out of the trash
+func (m *EventModel) Restore(orgId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE events
+		SET deleted_at = NULL, deleted_by = NULL, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NOT NULL
+	`
+	// Its room may have been booked while it was in the trash
+	_, err := m.DB.ExecContext(ctx, query, id, orgId)
+	return translateEventError(err)
+}
+
+// ✅ Purge — permanently removes events deleted before the cutoff, with
+// everything that cascades from them. Orders go first: their items and promo
+// codes would otherwise block the ticket types and codes from cascading.
+func (m *EventModel) Purge(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM orders
+		WHERE event_id IN (SELECT id FROM events WHERE deleted_at < $1)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM events WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Guest is a plus-one brought along on someone else's registration
+type Guest struct {
+	Id         int    `json:"Id"`
+	AttendeeId int    `json:"AttendeeId"`
+	Name       string `json:"Name"`
+	Email      string `json:"Email,omitempty"`
+}
+
+// insertGuests stores a registration's guests inside the caller's transaction
+func insertGuests(ctx context.Context, tx *sql.Tx, attendeeId int, guests []Guest) error {
+	query := `
+		INSERT INTO attendee_guests (attendee_id, name, email)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING id
+	`
+	for i := range guests {
+		guests[i].AttendeeId = attendeeId
+		if err := tx.QueryRowContext(ctx, query, attendeeId, guests[i].Name, guests[i].Email).Scan(&guests[i].Id); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// ✅ GetGuestsByEvent — every guest for an event keyed by attendee ID
+func (m *AttendeeModel) GetGuestsByEvent(eventId int) (map[int][]Guest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT g.id, g.attendee_id, g.name, COALESCE(g.email, '')
+		FROM attendee_guests g
+		JOIN attendees a ON a.id = g.attendee_id
+		WHERE a.event_id = $1 AND a.deleted_at IS NULL
+		ORDER BY g.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guests := map[int][]Guest{}
+	for rows.Next() {
+		var g Guest
+		if err := rows.Scan(&g.Id, &g.AttendeeId, &g.Name, &g.Email); err != nil {
+			return nil, err
+		}
+		guests[g.AttendeeId] = append(guests[g.AttendeeId], g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return guests, nil
+}
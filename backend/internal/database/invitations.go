@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrInvitationUnusable is returned when an invitation is expired, revoked or used up
+var ErrInvitationUnusable = errors.New("invitation is expired, revoked or used up")
+
+type InvitationModel struct {
+	DB *sql.DB
+}
+
+type Invitation struct {
+	Id        int        `json:"Id"`
+	EventId   int        `json:"EventId"`
+	Email     string     `json:"Email,omitempty"`
+	MaxUses   *int       `json:"MaxUses"`
+	Uses      int        `json:"Uses"`
+	ExpiresAt *time.Time `json:"ExpiresAt"`
+	RevokedAt *time.Time `json:"RevokedAt,omitempty"`
+	CreatedBy int        `json:"CreatedBy"`
+	TokenHash string     `json:"-"`
+}
+
+// Usable reports whether the invitation can still be redeemed
+func (i *Invitation) Usable() bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && time.Now().After(*i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == nil || i.Uses < *i.MaxUses
+}
+
+const invitationColumns = `id, event_id, COALESCE(email, ''), max_uses, uses, expires_at, revoked_at, created_by, token_hash`
+
+func scanInvitation(row rowScanner) (*Invitation, error) {
+	var inv Invitation
+	err := row.Scan(
+		&inv.Id,
+		&inv.EventId,
+		&inv.Email,
+		&inv.MaxUses,
+		&inv.Uses,
+		&inv.ExpiresAt,
+		&inv.RevokedAt,
+		&inv.CreatedBy,
+		&inv.TokenHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ✅ Insert — stores a new invitation; only the token hash is kept
+func (m *InvitationModel) Insert(inv *Invitation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_invitations (event_id, token_hash, email, max_uses, expires_at, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING id
+	`
+	err := m.DB.QueryRowContext(ctx, query,
+		inv.EventId,
+		inv.TokenHash,
+		inv.Email,
+		inv.MaxUses,
+		inv.ExpiresAt,
+		inv.CreatedBy,
+	).Scan(&inv.Id)
+	return translateError(err)
+}
+
+// ✅ GetByTokenHash — looks up an invitation from a presented token
+func (m *InvitationModel) GetByTokenHash(tokenHash string) (*Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + invitationColumns + ` FROM event_invitations WHERE token_hash = $1`
+	inv, err := scanInvitation(m.DB.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ✅ GetByEvent — all invitations issued for an event
+func (m *InvitationModel) GetByEvent(eventId int) ([]*Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + invitationColumns + ` FROM event_invitations WHERE event_id = $1 ORDER BY created_at DESC`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]*Invitation, 0)
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// ✅ Revoke — stops an invitation from being redeemed
+func (m *InvitationModel) Revoke(eventId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE event_invitations SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND event_id = $2 AND revoked_at IS NULL`
+	_, err := m.DB.ExecContext(ctx, query, id, eventId)
+	return err
+}
+
+// ✅ Redeem — counts one use and registers the user as an attendee, with their
+// answers and guests, in a single transaction. Returns ErrInvitationUnusable if the invitation can no longer be used.
+func (m *InvitationModel) Redeem(invitationId, userId int, answers Answers, guests []Guest) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE event_invitations
+		SET uses = uses + 1
+		WHERE id = $1
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > NOW())
+			AND (max_uses IS NULL OR uses < max_uses)
+		RETURNING event_id
+	`
+	attendee := Attendee{UserId: userId, Status: AttendeeStatusConfirmed}
+	if err := tx.QueryRowContext(ctx, query, invitationId).Scan(&attendee.EventId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvitationUnusable
+		}
+		return nil, err
+	}
+
+	if err := insertAttendee(ctx, tx, &attendee, answers, guests, true); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
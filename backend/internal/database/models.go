@@ -6,6 +6,18 @@ type Models struct {
 	Users     UserModel
 	Events    EventModel
 	Attendees AttendeeModel
+
+	Organizations OrganizationModel
+	EventStaff    EventStaffModel
+	Invitations   InvitationModel
+	Questions     QuestionModel
+	TicketTypes   TicketTypeModel
+	Orders        OrderModel
+	PromoCodes    PromoCodeModel
+	TicketHistory TicketHistoryModel
+	Audit         AuditModel
+	Venues        VenueModel
+	Categories    CategoryModel
 }
 
 func NewModels(db *sql.DB) Models {
@@ -13,5 +25,17 @@ func NewModels(db *sql.DB) Models {
 		Users:     UserModel{DB: db},
 		Events:    EventModel{DB: db},
 		Attendees: AttendeeModel{DB: db},
+
+		Organizations: OrganizationModel{DB: db},
+		EventStaff:    EventStaffModel{DB: db},
+		Invitations:   InvitationModel{DB: db},
+		Questions:     QuestionModel{DB: db},
+		TicketTypes:   TicketTypeModel{DB: db},
+		Orders:        OrderModel{DB: db},
+		PromoCodes:    PromoCodeModel{DB: db},
+		TicketHistory: TicketHistoryModel{DB: db},
+		Audit:         AuditModel{DB: db},
+		Venues:        VenueModel{DB: db},
+		Categories:    CategoryModel{DB: db},
 	}
 }
\ No newline at end of file
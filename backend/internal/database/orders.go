@@ -0,0 +1,423 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusFailed    = "failed"
+	OrderStatusExpired   = "expired"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// TicketError explains why a ticket type cannot be ordered
+type TicketError struct {
+	TicketTypeId int
+	Reason       string
+}
+
+func (e *TicketError) Error() string {
+	return fmt.Sprintf("ticket type %d: %s", e.TicketTypeId, e.Reason)
+}
+
+type OrderModel struct {
+	DB *sql.DB
+}
+
+type OrderItem struct {
+	Id             int   `json:"Id"`
+	TicketTypeId   int   `json:"TicketTypeId"`
+	Quantity       int   `json:"Quantity"`
+	UnitPriceCents int64 `json:"UnitPriceCents"`
+}
+
+// OrderRegistration holds what the buyer submitted at checkout so the
+// registration can be created once payment clears
+type OrderRegistration struct {
+	Answers Answers `json:"answers"`
+	Guests  []Guest `json:"guests"`
+}
+
+type Order struct {
+	Id               int               `json:"Id"`
+	EventId          int               `json:"EventId"`
+	UserId           int               `json:"UserId"`
+	Status           string            `json:"Status"`
+	TotalCents       int64             `json:"TotalCents"`
+	Currency         string            `json:"Currency"`
+	PromoCode        string            `json:"PromoCode,omitempty"`
+	PromoCodeId      *int              `json:"PromoCodeId,omitempty"`
+	DiscountCents    int64             `json:"DiscountCents"`
+	PaymentProvider  string            `json:"PaymentProvider"`
+	PaymentReference string            `json:"PaymentReference,omitempty"`
+	ExpiresAt        time.Time         `json:"ExpiresAt"`
+	PaidAt           *time.Time        `json:"PaidAt,omitempty"`
+	RefundedCents    int64             `json:"RefundedCents"`
+	RefundedAt       *time.Time        `json:"RefundedAt,omitempty"`
+	CreatedAt        time.Time         `json:"CreatedAt"`
+	Items            []OrderItem       `json:"Items"`
+	Registration     OrderRegistration `json:"-"`
+}
+
+// Quantity is the number of tickets in the order
+func (o *Order) Quantity() int {
+	n := 0
+	for _, item := range o.Items {
+		n += item.Quantity
+	}
+	return n
+}
+
+const orderColumns = `o.id, o.event_id, o.user_id, o.status, o.total_cents, o.currency, o.registration,
+	o.promo_code_id, COALESCE((SELECT pc.code FROM promo_codes pc WHERE pc.id = o.promo_code_id), ''), o.discount_cents,
+	o.payment_provider, COALESCE(o.payment_reference, ''), o.expires_at, o.paid_at,
+	o.refunded_cents, o.refunded_at, o.created_at`
+
+func scanOrder(row rowScanner) (*Order, error) {
+	var o Order
+	var registration []byte
+	err := row.Scan(
+		&o.Id,
+		&o.EventId,
+		&o.UserId,
+		&o.Status,
+		&o.TotalCents,
+		&o.Currency,
+		&registration,
+		&o.PromoCodeId,
+		&o.PromoCode,
+		&o.DiscountCents,
+		&o.PaymentProvider,
+		&o.PaymentReference,
+		&o.ExpiresAt,
+		&o.PaidAt,
+		&o.RefundedCents,
+		&o.RefundedAt,
+		&o.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(registration, &o.Registration); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ✅ Create — reserves inventory and records a pending order. Ticket types are
+// locked for the duration so two buyers cannot take the same last ticket.
+// The order's items must carry TicketTypeId and Quantity; prices are filled in,
+// and PromoCode, if set, is validated and discounted.
+func (m *OrderModel) Create(order *Order) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := reserveSeats(ctx, tx, order.EventId, order.Quantity()); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	order.TotalCents = 0
+	order.Currency = ""
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		// Lock the tier, then count what is already spoken for
+		var t TicketType
+		err := tx.QueryRowContext(ctx, `
+			SELECT id, price_cents, currency, quantity, sales_start, sales_end
+			FROM ticket_types
+			WHERE id = $1 AND event_id = $2
+			FOR UPDATE
+		`, item.TicketTypeId, order.EventId).Scan(&t.Id, &t.PriceCents, &t.Currency, &t.Quantity, &t.SalesStart, &t.SalesEnd)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &TicketError{TicketTypeId: item.TicketTypeId, Reason: "does not exist"}
+			}
+			return err
+		}
+		if !t.OnSale(now) {
+			return &TicketError{TicketTypeId: t.Id, Reason: "is not on sale"}
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(oi.quantity), 0)
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE oi.ticket_type_id = $1
+				AND (o.status = 'paid' OR (o.status = 'pending' AND o.expires_at > NOW()))
+		`, t.Id).Scan(&t.Sold)
+		if err != nil {
+			return err
+		}
+		if t.Available() < item.Quantity {
+			return &TicketError{TicketTypeId: t.Id, Reason: fmt.Sprintf("only %d left", t.Available())}
+		}
+
+		if order.Currency == "" {
+			order.Currency = t.Currency
+		} else if order.Currency != t.Currency {
+			return &TicketError{TicketTypeId: t.Id, Reason: "uses a different currency from the rest of the order"}
+		}
+		item.UnitPriceCents = t.PriceCents
+		order.TotalCents += t.PriceCents * int64(item.Quantity)
+	}
+
+	order.PromoCodeId = nil
+	order.DiscountCents = 0
+	if order.PromoCode != "" {
+		if err := applyPromoCode(ctx, tx, order, now); err != nil {
+			return err
+		}
+	}
+
+	registration, err := json.Marshal(order.Registration)
+	if err != nil {
+		return err
+	}
+
+	order.Status = OrderStatusPending
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO orders (event_id, user_id, status, total_cents, currency, registration, promo_code_id, discount_cents,
+			payment_provider, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`,
+		order.EventId,
+		order.UserId,
+		order.Status,
+		order.TotalCents,
+		order.Currency,
+		registration,
+		order.PromoCodeId,
+		order.DiscountCents,
+		order.PaymentProvider,
+		order.ExpiresAt,
+	).Scan(&order.Id, &order.CreatedAt)
+	if err != nil {
+		return translateError(err)
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO order_items (order_id, ticket_type_id, quantity, unit_price_cents)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, order.Id, item.TicketTypeId, item.Quantity, item.UnitPriceCents).Scan(&item.Id)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *OrderModel) loadItems(ctx context.Context, order *Order) error {
+	rows, err := m.DB.QueryContext(ctx,
+		`SELECT id, ticket_type_id, quantity, unit_price_cents FROM order_items WHERE order_id = $1 ORDER BY id`,
+		order.Id,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Items = make([]OrderItem, 0)
+	for rows.Next() {
+		var item OrderItem
+		if err := rows.Scan(&item.Id, &item.TicketTypeId, &item.Quantity, &item.UnitPriceCents); err != nil {
+			return err
+		}
+		order.Items = append(order.Items, item)
+	}
+	return rows.Err()
+}
+
+func (m *OrderModel) getOrder(query string, args ...interface{}) (*Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	order, err := scanOrder(m.DB.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := m.loadItems(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ✅ Get — one order with its items
+func (m *OrderModel) Get(id int) (*Order, error) {
+	return m.getOrder(`SELECT `+orderColumns+` FROM orders o WHERE o.id = $1`, id)
+}
+
+// ✅ GetByPaymentReference — finds the order a provider webhook is about
+func (m *OrderModel) GetByPaymentReference(provider, reference string) (*Order, error) {
+	return m.getOrder(
+		`SELECT `+orderColumns+` FROM orders o WHERE o.payment_provider = $1 AND o.payment_reference = $2`,
+		provider, reference,
+	)
+}
+
+// ✅ GetPendingForUser — the user's open checkout for an event, if any
+func (m *OrderModel) GetPendingForUser(eventId, userId int) (*Order, error) {
+	return m.getOrder(`
+		SELECT `+orderColumns+`
+		FROM orders o
+		WHERE o.event_id = $1 AND o.user_id = $2 AND o.status = 'pending' AND o.expires_at > NOW()
+		ORDER BY o.id DESC
+		LIMIT 1
+	`, eventId, userId)
+}
+
+func (m *OrderModel) queryOrders(query string, args ...interface{}) ([]*Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*Order, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if err := m.loadItems(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+// ✅ GetByUser — a user's orders, newest first
+func (m *OrderModel) GetByUser(userId int) ([]*Order, error) {
+	return m.queryOrders(`SELECT `+orderColumns+` FROM orders o WHERE o.user_id = $1 ORDER BY o.id DESC`, userId)
+}
+
+// ✅ GetByEvent — every order placed for an event
+func (m *OrderModel) GetByEvent(eventId int) ([]*Order, error) {
+	return m.queryOrders(`SELECT `+orderColumns+` FROM orders o WHERE o.event_id = $1 ORDER BY o.id DESC`, eventId)
+}
+
+// ✅ GetByPromoCode — orders that used a promo code
+func (m *OrderModel) GetByPromoCode(promoCodeId int) ([]*Order, error) {
+	return m.queryOrders(`SELECT `+orderColumns+` FROM orders o WHERE o.promo_code_id = $1 ORDER BY o.id DESC`, promoCodeId)
+}
+
+// ✅ GetExpired — pending orders whose reservation has lapsed
+func (m *OrderModel) GetExpired() ([]*Order, error) {
+	return m.queryOrders(`SELECT ` + orderColumns + ` FROM orders o WHERE o.status = 'pending' AND o.expires_at <= NOW()`)
+}
+
+// ✅ SetPaymentReference — links the order to the provider's payment
+func (m *OrderModel) SetPaymentReference(id int, reference string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `UPDATE orders SET payment_reference = $1 WHERE id = $2`, reference, id)
+	return translateError(err)
+}
+
+// ✅ MarkPaid — settles a pending order and registers the buyer with the
+// answers and guests captured at checkout. Returns nil if the order was no
+// longer pending, which makes repeated webhooks harmless, or if its
+// reservation lapsed and the seats may already be sold to someone else.
+func (m *OrderModel) MarkPaid(id int) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE orders o
+		SET status = 'paid', paid_at = CURRENT_TIMESTAMP
+		WHERE o.id = $1 AND o.status = 'pending' AND o.expires_at > NOW()
+		RETURNING `+orderColumns,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	attendee := Attendee{
+		EventId: order.EventId,
+		UserId:  order.UserId,
+		Status:  AttendeeStatusConfirmed,
+		OrderId: &order.Id,
+	}
+	if err := insertAttendee(ctx, tx, &attendee, order.Registration.Answers, order.Registration.Guests, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+// ✅ MarkRefunded — records that the payment for an order that was closed
+// without being fulfilled went back to the buyer
+func (m *OrderModel) MarkRefunded(id int, amountCents int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `
+		UPDATE orders
+		SET status = 'refunded', refunded_cents = $2, refunded_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status IN ('failed', 'expired', 'cancelled')
+	`, id, amountCents)
+	return err
+}
+
+// ✅ Close — moves a pending order to failed, expired or cancelled, which
+// releases its tickets. Returns false if the order was no longer pending.
+func (m *OrderModel) Close(id int, status string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `UPDATE orders SET status = $1 WHERE id = $2 AND status = 'pending'`, status, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,274 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const DefaultOrganizationSlug = "default"
+
+// ErrLastOwner is returned when an account that is the only owner of an
+// organization would be deleted
+var ErrLastOwner = errors.New("user is the last owner of an organization")
+
+// ownsAloneQuery checks whether user $1 is an organization's only live owner
+const ownsAloneQuery = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM organization_members om
+		WHERE om.user_id = $1 AND om.role = 'owner'
+			AND NOT EXISTS (
+				SELECT 1
+				FROM organization_members other
+				JOIN users u ON u.id = other.user_id
+				WHERE other.organization_id = om.organization_id AND other.role = 'owner'
+					AND other.user_id <> $1 AND u.deleted_at IS NULL
+			)
+	)
+`
+
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+type OrganizationModel struct {
+	DB *sql.DB
+}
+
+type Organization struct {
+	Id   int    `json:"Id"`
+	Name string `json:"Name"`
+	Slug string `json:"Slug"`
+	// Role of the requesting user, filled in when listing their organizations
+	Role string `json:"Role,omitempty"`
+}
+
+type OrganizationMember struct {
+	OrganizationId int    `json:"OrganizationId"`
+	UserId         int    `json:"UserId"`
+	Name           string `json:"Name"`
+	// Only shown to the organization's admins
+	Email string `json:"Email,omitempty"`
+	Role  string `json:"Role"`
+}
+
+// CanManage reports whether a role may administer the organization and its events
+func CanManage(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin
+}
+
+// ✅ Insert — creates the organization and makes the creator its owner
+func (m *OrganizationModel) Insert(org *Organization, ownerId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organizations (name, slug) VALUES ($1, $2) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, org.Name, org.Slug).Scan(&org.Id); err != nil {
+		return translateError(err)
+	}
+
+	query = `INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, query, org.Id, ownerId, OrgRoleOwner); err != nil {
+		return translateError(err)
+	}
+	org.Role = OrgRoleOwner
+
+	return tx.Commit()
+}
+
+func (m *OrganizationModel) getOrganization(query string, args ...interface{}) (*Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var org Organization
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&org.Id, &org.Name, &org.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ✅ Get — retrieves one organization by ID
+func (m *OrganizationModel) Get(id int) (*Organization, error) {
+	return m.getOrganization(`SELECT id, name, slug FROM organizations WHERE id = $1`, id)
+}
+
+// ✅ GetDefault — the shared organization every user belongs to
+func (m *OrganizationModel) GetDefault() (*Organization, error) {
+	return m.getOrganization(`SELECT id, name, slug FROM organizations WHERE slug = $1`, DefaultOrganizationSlug)
+}
+
+// ✅ GetForUser — organizations the user is a member of, with their role
+func (m *OrganizationModel) GetForUser(userId int) ([]*Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT o.id, o.name, o.slug, om.role
+		FROM organizations o
+		JOIN organization_members om ON o.id = om.organization_id
+		WHERE om.user_id = $1
+		ORDER BY o.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := make([]*Organization, 0)
+	for rows.Next() {
+		var org Organization
+		if err := rows.Scan(&org.Id, &org.Name, &org.Slug, &org.Role); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, &org)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// ✅ GetRole — the user's role in the organization, or "" if they are not a member
+func (m *OrganizationModel) GetRole(orgId, userId int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	var role string
+	err := m.DB.QueryRowContext(ctx, query, orgId, userId).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// ✅ GetMembers — everyone in the organization
+func (m *OrganizationModel) GetMembers(orgId int) ([]*OrganizationMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT om.organization_id, u.id, u.name, u.email, om.role
+		FROM organization_members om
+		JOIN users u ON u.id = om.user_id
+		WHERE om.organization_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, orgId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*OrganizationMember, 0)
+	for rows.Next() {
+		var member OrganizationMember
+		if err := rows.Scan(&member.OrganizationId, &member.UserId, &member.Name, &member.Email, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// ✅ AddMember — inserts a membership; ErrDuplicate if the user already belongs
+func (m *OrganizationModel) AddMember(orgId, userId int, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)`
+	_, err := m.DB.ExecContext(ctx, query, orgId, userId, role)
+	return translateError(err)
+}
+
+// ✅ SetRole — changes an existing member's role
+func (m *OrganizationModel) SetRole(orgId, userId int, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2`
+	_, err := m.DB.ExecContext(ctx, query, orgId, userId, role)
+	return translateError(err)
+}
+
+// ✅ AssignDefaultOwner — makes the account with the given email an owner of
+// the default organization. Returns false if there is no such account yet.
+func (m *OrganizationModel) AssignDefaultOwner(email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		SELECT o.id, u.id, $3
+		FROM organizations o, users u
+		WHERE o.slug = $1 AND u.email = $2 AND u.deleted_at IS NULL
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	result, err := m.DB.ExecContext(ctx, query, DefaultOrganizationSlug, email, OrgRoleOwner)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ✅ RemoveMember — drops a membership
+func (m *OrganizationModel) RemoveMember(orgId, userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+	_, err := m.DB.ExecContext(ctx, query, orgId, userId)
+	return err
+}
+
+// ✅ IsLastOwner — whether the user is the only owner of any organization
+func (m *OrganizationModel) IsLastOwner(userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var last bool
+	err := m.DB.QueryRowContext(ctx, ownsAloneQuery, userId).Scan(&last)
+	return last, err
+}
+
+// ✅ CountOwners — used to stop the last owner from leaving
+func (m *OrganizationModel) CountOwners(orgId int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2`
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, orgId, OrgRoleOwner).Scan(&count)
+	return count, err
+}
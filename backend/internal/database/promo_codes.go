@@ -0,0 +1,344 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// ErrPromoCodeInUse is returned when deleting a promo code that has been redeemed
+var ErrPromoCodeInUse = errors.New("promo code has been redeemed")
+
+// PromoCodeError explains why a promo code cannot be applied to an order
+type PromoCodeError struct {
+	Code   string
+	Reason string
+}
+
+func (e *PromoCodeError) Error() string {
+	return "promo code " + e.Code + " " + e.Reason
+}
+
+type PromoCodeModel struct {
+	DB *sql.DB
+}
+
+type PromoCode struct {
+	Id           int    `json:"Id"`
+	EventId      int    `json:"EventId"`
+	Code         string `json:"Code"`
+	DiscountType string `json:"DiscountType"`
+	// A percentage (1-100) or an amount in cents of the order's currency
+	DiscountValue int64 `json:"DiscountValue"`
+	// Empty means every ticket type of the event
+	TicketTypeIds []int      `json:"TicketTypeIds"`
+	MaxUses       *int       `json:"MaxUses"`
+	StartsAt      *time.Time `json:"StartsAt"`
+	ExpiresAt     *time.Time `json:"ExpiresAt"`
+	Active        bool       `json:"Active"`
+	// Paid orders plus pending ones that still hold a reservation
+	Uses int `json:"Uses"`
+}
+
+// PromoCodeReport summarizes what a code has been used for
+type PromoCodeReport struct {
+	PromoCodeId   int    `json:"PromoCodeId"`
+	Code          string `json:"Code"`
+	Redemptions   int    `json:"Redemptions"`
+	Pending       int    `json:"Pending"`
+	Tickets       int    `json:"Tickets"`
+	DiscountCents int64  `json:"DiscountCents"`
+	RevenueCents  int64  `json:"RevenueCents"`
+}
+
+// NormalizePromoCode is the form codes are stored and looked up in
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// appliesTo reports whether the code covers a ticket type
+func (p *PromoCode) appliesTo(ticketTypeId int) bool {
+	if len(p.TicketTypeIds) == 0 {
+		return true
+	}
+	for _, id := range p.TicketTypeIds {
+		if id == ticketTypeId {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount works out how much the code takes off the given items. Fixed
+// discounts apply once per order and never exceed the eligible subtotal.
+func (p *PromoCode) Discount(items []OrderItem) int64 {
+	var eligible int64
+	for _, item := range items {
+		if p.appliesTo(item.TicketTypeId) {
+			eligible += item.UnitPriceCents * int64(item.Quantity)
+		}
+	}
+
+	switch p.DiscountType {
+	case DiscountPercent:
+		return eligible * p.DiscountValue / 100
+	case DiscountFixed:
+		if p.DiscountValue < eligible {
+			return p.DiscountValue
+		}
+		return eligible
+	}
+	return 0
+}
+
+// Unusable returns why the code cannot be redeemed right now, or "" if it can
+func (p *PromoCode) Unusable(now time.Time) string {
+	switch {
+	case !p.Active:
+		return "is no longer active"
+	case p.StartsAt != nil && now.Before(*p.StartsAt):
+		return "is not valid yet"
+	case p.ExpiresAt != nil && !now.Before(*p.ExpiresAt):
+		return "has expired"
+	case p.MaxUses != nil && p.Uses >= *p.MaxUses:
+		return "has reached its usage limit"
+	}
+	return ""
+}
+
+const promoCodeColumns = `p.id, p.event_id, p.code, p.discount_type, p.discount_value, p.ticket_type_ids,
+	p.max_uses, p.starts_at, p.expires_at, p.active,
+	(SELECT COUNT(*) FROM orders o
+		WHERE o.promo_code_id = p.id AND (o.status = 'paid' OR (o.status = 'pending' AND o.expires_at > NOW())))`
+
+func scanPromoCode(row rowScanner) (*PromoCode, error) {
+	var p PromoCode
+	var ticketTypeIds pq.Int64Array
+	err := row.Scan(
+		&p.Id,
+		&p.EventId,
+		&p.Code,
+		&p.DiscountType,
+		&p.DiscountValue,
+		&ticketTypeIds,
+		&p.MaxUses,
+		&p.StartsAt,
+		&p.ExpiresAt,
+		&p.Active,
+		&p.Uses,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.TicketTypeIds = make([]int, len(ticketTypeIds))
+	for i, id := range ticketTypeIds {
+		p.TicketTypeIds[i] = int(id)
+	}
+	return &p, nil
+}
+
+// ✅ Insert — creates a promo code for an event
+func (m *PromoCodeModel) Insert(p *PromoCode) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO promo_codes (event_id, code, discount_type, discount_value, ticket_type_ids, max_uses, starts_at, expires_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	p.Code = NormalizePromoCode(p.Code)
+	err := m.DB.QueryRowContext(ctx, query,
+		p.EventId,
+		p.Code,
+		p.DiscountType,
+		p.DiscountValue,
+		pq.Array(p.TicketTypeIds),
+		p.MaxUses,
+		p.StartsAt,
+		p.ExpiresAt,
+		p.Active,
+	).Scan(&p.Id)
+	return translateError(err)
+}
+
+// ✅ GetByEvent — an event's promo codes with their usage
+func (m *PromoCodeModel) GetByEvent(eventId int) ([]*PromoCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes p WHERE p.event_id = $1 ORDER BY p.code`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := make([]*PromoCode, 0)
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ✅ Get — one promo code of an event
+func (m *PromoCodeModel) Get(eventId, id int) (*PromoCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes p WHERE p.event_id = $1 AND p.id = $2`
+
+	p, err := scanPromoCode(m.DB.QueryRowContext(ctx, query, eventId, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// ✅ Update — changes a promo code's terms
+func (m *PromoCodeModel) Update(p *PromoCode) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE promo_codes
+		SET code = $1, discount_type = $2, discount_value = $3, ticket_type_ids = $4, max_uses = $5,
+			starts_at = $6, expires_at = $7, active = $8
+		WHERE id = $9 AND event_id = $10
+	`
+	p.Code = NormalizePromoCode(p.Code)
+	_, err := m.DB.ExecContext(ctx, query,
+		p.Code,
+		p.DiscountType,
+		p.DiscountValue,
+		pq.Array(p.TicketTypeIds),
+		p.MaxUses,
+		p.StartsAt,
+		p.ExpiresAt,
+		p.Active,
+		p.Id,
+		p.EventId,
+	)
+	return translateError(err)
+}
+
+// ✅ Delete — removes a promo code that no order has used
+func (m *PromoCodeModel) Delete(eventId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var orders int
+	err := m.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders WHERE promo_code_id = $1`, id).Scan(&orders)
+	if err != nil {
+		return err
+	}
+	if orders > 0 {
+		return ErrPromoCodeInUse
+	}
+
+	_, err = m.DB.ExecContext(ctx, `DELETE FROM promo_codes WHERE id = $1 AND event_id = $2`, id, eventId)
+	return err
+}
+
+// ✅ GetReport — redemptions, discounts and revenue for each of an event's codes
+func (m *PromoCodeModel) GetReport(eventId int) ([]*PromoCodeReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT p.id, p.code,
+			COUNT(o.id) FILTER (WHERE o.status = 'paid'),
+			COUNT(o.id) FILTER (WHERE o.status = 'pending' AND o.expires_at > NOW()),
+			COALESCE(SUM(q.tickets) FILTER (WHERE o.status = 'paid'), 0),
+			COALESCE(SUM(o.discount_cents) FILTER (WHERE o.status = 'paid'), 0),
+			COALESCE(SUM(o.total_cents) FILTER (WHERE o.status = 'paid'), 0)
+		FROM promo_codes p
+		LEFT JOIN orders o ON o.promo_code_id = p.id
+		LEFT JOIN (
+			SELECT order_id, SUM(quantity) AS tickets FROM order_items GROUP BY order_id
+		) q ON q.order_id = o.id
+		WHERE p.event_id = $1
+		GROUP BY p.id
+		ORDER BY p.code
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]*PromoCodeReport, 0)
+	for rows.Next() {
+		var r PromoCodeReport
+		err := rows.Scan(&r.PromoCodeId, &r.Code, &r.Redemptions, &r.Pending, &r.Tickets, &r.DiscountCents, &r.RevenueCents)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// applyPromoCode locks the code for the rest of the transaction so its usage
+// limit holds under concurrent checkouts, then discounts the priced order
+func applyPromoCode(ctx context.Context, tx *sql.Tx, order *Order, now time.Time) error {
+	code := NormalizePromoCode(order.PromoCode)
+
+	// Lock the row first so the usage count below sees every committed order
+	var id int
+	err := tx.QueryRowContext(ctx,
+		`SELECT id FROM promo_codes WHERE event_id = $1 AND code = $2 FOR UPDATE`,
+		order.EventId, code,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &PromoCodeError{Code: code, Reason: "does not exist"}
+		}
+		return err
+	}
+
+	p, err := scanPromoCode(tx.QueryRowContext(ctx, `SELECT `+promoCodeColumns+` FROM promo_codes p WHERE p.id = $1`, id))
+	if err != nil {
+		return err
+	}
+	if reason := p.Unusable(now); reason != "" {
+		return &PromoCodeError{Code: code, Reason: reason}
+	}
+
+	discount := p.Discount(order.Items)
+	if discount == 0 {
+		return &PromoCodeError{Code: code, Reason: "does not apply to the selected tickets"}
+	}
+
+	order.PromoCode = p.Code
+	order.PromoCodeId = &p.Id
+	order.DiscountCents = discount
+	order.TotalCents -= discount
+	return nil
+}
@@ -0,0 +1,317 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	QuestionTypeText        = "text"
+	QuestionTypeChoice      = "choice"
+	QuestionTypeMultiChoice = "multi_choice"
+	QuestionTypeNumber      = "number"
+)
+
+const maxTextAnswerLength = 2000
+
+// ErrQuestionTypeLocked is returned when changing the type of a question
+// that already has answers, which would no longer fit it
+var ErrQuestionTypeLocked = errors.New("question type cannot change once answered")
+
+type QuestionModel struct {
+	DB *sql.DB
+}
+
+type Question struct {
+	Id       int      `json:"Id"`
+	EventId  int      `json:"EventId"`
+	Label    string   `json:"Label"`
+	Type     string   `json:"Type"`
+	Options  []string `json:"Options"`
+	Required bool     `json:"Required"`
+	Position int      `json:"Position"`
+}
+
+// Answers maps a question ID to the raw JSON value submitted for it
+type Answers map[int]json.RawMessage
+
+// CheckDefinition validates the question itself, before it is saved
+func (q *Question) CheckDefinition() error {
+	switch q.Type {
+	case QuestionTypeText, QuestionTypeNumber:
+		if len(q.Options) > 0 {
+			return fmt.Errorf("%s questions cannot have options", q.Type)
+		}
+	case QuestionTypeChoice, QuestionTypeMultiChoice:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("%s questions need at least two options", q.Type)
+		}
+		seen := map[string]bool{}
+		for _, o := range q.Options {
+			if o == "" || seen[o] {
+				return fmt.Errorf("options must be unique and non-empty")
+			}
+			seen[o] = true
+		}
+	default:
+		return fmt.Errorf("type must be text, choice, multi_choice or number")
+	}
+	return nil
+}
+
+// CheckAnswer validates one submitted value against the question's type
+func (q *Question) CheckAnswer(value json.RawMessage) error {
+	switch q.Type {
+	case QuestionTypeText:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("must be a string")
+		}
+		if len(s) > maxTextAnswerLength {
+			return fmt.Errorf("must be at most %d characters", maxTextAnswerLength)
+		}
+		if q.Required && s == "" {
+			return fmt.Errorf("is required")
+		}
+	case QuestionTypeNumber:
+		var n float64
+		if err := json.Unmarshal(value, &n); err != nil {
+			return fmt.Errorf("must be a number")
+		}
+	case QuestionTypeChoice:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("must be a string")
+		}
+		if !q.hasOption(s) {
+			return fmt.Errorf("must be one of the listed options")
+		}
+	case QuestionTypeMultiChoice:
+		var list []string
+		if err := json.Unmarshal(value, &list); err != nil {
+			return fmt.Errorf("must be a list of strings")
+		}
+		if q.Required && len(list) == 0 {
+			return fmt.Errorf("is required")
+		}
+		for _, s := range list {
+			if !q.hasOption(s) {
+				return fmt.Errorf("must only contain listed options")
+			}
+		}
+	}
+	return nil
+}
+
+func (q *Question) hasOption(s string) bool {
+	for _, o := range q.Options {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAnswers checks a set of answers against an event's questions and
+// returns a message per failing question ID. Unknown question IDs are rejected.
+// Required questions may only be skipped when enforceRequired is false, which
+// is the case when an organizer registers someone else.
+func ValidateAnswers(questions []*Question, answers Answers, enforceRequired bool) map[int]string {
+	problems := map[int]string{}
+	byId := make(map[int]*Question, len(questions))
+	for _, q := range questions {
+		byId[q.Id] = q
+		value, ok := answers[q.Id]
+		if !ok || string(value) == "null" {
+			if q.Required && enforceRequired {
+				problems[q.Id] = "is required"
+			}
+			continue
+		}
+		if err := q.CheckAnswer(value); err != nil {
+			problems[q.Id] = err.Error()
+		}
+	}
+	for id := range answers {
+		if _, ok := byId[id]; !ok {
+			problems[id] = "is not a question for this event"
+		}
+	}
+	return problems
+}
+
+// ✅ GetByEvent — an event's questions in display order
+func (m *QuestionModel) GetByEvent(eventId int) ([]*Question, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, event_id, label, type, options, required, position
+		FROM event_questions
+		WHERE event_id = $1
+		ORDER BY position, id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := make([]*Question, 0)
+	for rows.Next() {
+		var q Question
+		var options []byte
+		if err := rows.Scan(&q.Id, &q.EventId, &q.Label, &q.Type, &options, &q.Required, &q.Position); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(options, &q.Options); err != nil {
+			return nil, err
+		}
+		questions = append(questions, &q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// ✅ Replace — swaps an event's schema for a new one. Questions keep their ID
+// when it is supplied, so answers to unchanged questions survive; questions
+// missing from the new schema are deleted along with their answers. An
+// answered question cannot change type; ErrQuestionTypeLocked says so.
+func (m *QuestionModel) Replace(eventId int, questions []*Question) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keep := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		if q.Id > 0 {
+			keep = append(keep, int64(q.Id))
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM event_questions WHERE event_id = $1 AND NOT (id = ANY($2))`,
+		eventId, pq.Array(keep),
+	); err != nil {
+		return err
+	}
+
+	for i, q := range questions {
+		q.EventId = eventId
+		q.Position = i
+		options, err := json.Marshal(q.Options)
+		if err != nil {
+			return err
+		}
+
+		if q.Id > 0 {
+			var currentType string
+			var answered bool
+			err := tx.QueryRowContext(ctx, `
+				SELECT q.type, EXISTS (SELECT 1 FROM attendee_answers aa WHERE aa.question_id = q.id)
+				FROM event_questions q
+				WHERE q.id = $1 AND q.event_id = $2
+				FOR UPDATE
+			`, q.Id, eventId).Scan(&currentType, &answered)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if answered && currentType != q.Type {
+				return fmt.Errorf("%w: %q", ErrQuestionTypeLocked, q.Label)
+			}
+
+			result, err := tx.ExecContext(ctx, `
+				UPDATE event_questions
+				SET label = $1, type = $2, options = $3, required = $4, position = $5
+				WHERE id = $6 AND event_id = $7
+			`, q.Label, q.Type, options, q.Required, q.Position, q.Id, eventId)
+			if err != nil {
+				return translateError(err)
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return err
+			} else if n == 1 {
+				continue
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO event_questions (event_id, label, type, options, required, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, eventId, q.Label, q.Type, options, q.Required, q.Position).Scan(&q.Id)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ✅ GetAnswersByEvent — every answer for an event keyed by attendee ID
+func (m *QuestionModel) GetAnswersByEvent(eventId int) (map[int]Answers, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT aa.attendee_id, aa.question_id, aa.value
+		FROM attendee_answers aa
+		JOIN attendees a ON a.id = aa.attendee_id
+		WHERE a.event_id = $1 AND a.deleted_at IS NULL
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := map[int]Answers{}
+	for rows.Next() {
+		var attendeeId, questionId int
+		var value []byte
+		if err := rows.Scan(&attendeeId, &questionId, &value); err != nil {
+			return nil, err
+		}
+		if answers[attendeeId] == nil {
+			answers[attendeeId] = Answers{}
+		}
+		answers[attendeeId][questionId] = json.RawMessage(value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// insertAnswers stores a registration's answers inside the caller's transaction
+func insertAnswers(ctx context.Context, tx *sql.Tx, attendeeId int, answers Answers) error {
+	for questionId, value := range answers {
+		if string(value) == "null" {
+			continue
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO attendee_answers (attendee_id, question_id, value) VALUES ($1, $2, $3)`,
+			attendeeId, questionId, []byte(value),
+		)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
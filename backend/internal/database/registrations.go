@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Registration is an attendee row joined with who registered and for what
+type Registration struct {
+	AttendeeId    int       `json:"AttendeeId"`
+	EventId       int       `json:"EventId"`
+	EventName     string    `json:"EventName"`
+	UserId        int       `json:"UserId"`
+	Name          string    `json:"Name"`
+	Email         string    `json:"Email"`
+	Status        string    `json:"Status"`
+	StatusMessage string    `json:"StatusMessage"`
+	CreatedAt     time.Time `json:"CreatedAt"`
+}
+
+func (m *AttendeeModel) queryRegistrations(query string, args ...interface{}) ([]*Registration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registrations := make([]*Registration, 0)
+	for rows.Next() {
+		var r Registration
+		if err := rows.Scan(
+			&r.AttendeeId,
+			&r.EventId,
+			&r.EventName,
+			&r.UserId,
+			&r.Name,
+			&r.Email,
+			&r.Status,
+			&r.StatusMessage,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		registrations = append(registrations, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return registrations, nil
+}
+
+const registrationSelect = `
+	SELECT a.id, e.id, e.name, u.id, u.name, u.email, a.status, a.status_message, a.created_at
+	FROM attendees a
+	JOIN events e ON e.id = a.event_id
+	JOIN users u ON u.id = a.user_id
+`
+
+// ✅ GetRegistrationsByEvent — registrations for an event, optionally filtered by status
+func (m *AttendeeModel) GetRegistrationsByEvent(eventId int, status string) ([]*Registration, error) {
+	query := registrationSelect + `
+		WHERE a.event_id = $1 AND ($2 = '' OR a.status = $2) AND a.deleted_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY a.created_at
+	`
+	return m.queryRegistrations(query, eventId, status)
+}
+
+// ✅ GetRegistrationsByUser — every registration a user has made, with its status
+func (m *AttendeeModel) GetRegistrationsByUser(userId int) ([]*Registration, error) {
+	query := registrationSelect + `
+		WHERE a.user_id = $1 AND a.deleted_at IS NULL AND e.deleted_at IS NULL
+		ORDER BY a.created_at DESC
+	`
+	return m.queryRegistrations(query, userId)
+}
+
+// ✅ Decide — moves pending registrations to confirmed or rejected and returns
+// the user IDs that actually changed
+func (m *AttendeeModel) Decide(eventId int, userIds []int, status, message string, decidedBy int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ids := make([]int64, len(userIds))
+	for i, id := range userIds {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		UPDATE attendees
+		SET status = $1, status_message = $2, decided_by = $3, decided_at = CURRENT_TIMESTAMP
+		WHERE event_id = $4 AND user_id = ANY($5) AND status = 'pending' AND deleted_at IS NULL
+		RETURNING user_id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, status, message, decidedBy, eventId, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changed := make([]int, 0, len(userIds))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		changed = append(changed, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
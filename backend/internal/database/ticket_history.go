@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	TicketActionCancelled   = "cancelled"
+	TicketActionRemoved     = "removed"
+	TicketActionTransferred = "transferred"
+)
+
+var (
+	// ErrAlreadyCheckedIn is returned when a used ticket is cancelled or transferred
+	ErrAlreadyCheckedIn = errors.New("attendee has already checked in")
+	// ErrAlreadyRegistered is returned when a ticket is transferred to someone who already attends
+	ErrAlreadyRegistered = errors.New("user is already registered for this event")
+)
+
+type TicketHistoryModel struct {
+	DB *sql.DB
+}
+
+// TicketHistoryEntry records one change of hands for a registration
+type TicketHistoryEntry struct {
+	Id              int       `json:"Id"`
+	EventId         int       `json:"EventId"`
+	OrderId         *int      `json:"OrderId,omitempty"`
+	Action          string    `json:"Action"`
+	FromUserId      *int      `json:"FromUserId,omitempty"`
+	ToUserId        *int      `json:"ToUserId,omitempty"`
+	ActorId         *int      `json:"ActorId,omitempty"`
+	RefundCents     int64     `json:"RefundCents"`
+	RefundReference string    `json:"RefundReference,omitempty"`
+	Note            string    `json:"Note,omitempty"`
+	CreatedAt       time.Time `json:"CreatedAt"`
+}
+
+func insertTicketHistory(ctx context.Context, tx *sql.Tx, entry *TicketHistoryEntry) error {
+	query := `
+		INSERT INTO ticket_history (event_id, order_id, action, from_user_id, to_user_id, actor_id, refund_cents, refund_reference, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+		RETURNING id, created_at
+	`
+	return tx.QueryRowContext(ctx, query,
+		entry.EventId,
+		entry.OrderId,
+		entry.Action,
+		entry.FromUserId,
+		entry.ToUserId,
+		entry.ActorId,
+		entry.RefundCents,
+		entry.RefundReference,
+		entry.Note,
+	).Scan(&entry.Id, &entry.CreatedAt)
+}
+
+// ✅ Cancel — gives up a registration. The attendee row is soft-deleted (its
+// answers and guests go when it is purged), a paid order is closed with
+// whatever was refunded, and the change is recorded. entry carries the
+// action, actor and refund details.
+func (m *TicketHistoryModel) Cancel(attendee *Attendee, entry *TicketHistoryEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE attendees SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND checked_in_at IS NULL AND deleted_at IS NULL`,
+		attendee.Id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAlreadyCheckedIn
+	}
+
+	if attendee.OrderId != nil {
+		// Closing the order returns its tickets to the pool
+		_, err := tx.ExecContext(ctx, `
+			UPDATE orders
+			SET status = CASE WHEN $2 > 0 THEN 'refunded' ELSE 'cancelled' END,
+				refunded_cents = refunded_cents + $2,
+				refunded_at = CASE WHEN $2 > 0 THEN CURRENT_TIMESTAMP ELSE refunded_at END
+			WHERE id = $1 AND status = 'paid'
+		`, *attendee.OrderId, entry.RefundCents)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry.EventId = attendee.EventId
+	entry.OrderId = attendee.OrderId
+	entry.FromUserId = &attendee.UserId
+	if err := insertTicketHistory(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ✅ Transfer — hands a registration to another user. A new attendee row is
+// created so the previous holder's ticket code stops working; answers,
+// guests and the order link move across unchanged.
+func (m *TicketHistoryModel) Transfer(attendee *Attendee, toUserId int, entry *TicketHistoryEntry) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var checkedInAt *time.Time
+	err = tx.QueryRowContext(ctx,
+		`SELECT checked_in_at FROM attendees WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		attendee.Id,
+	).Scan(&checkedInAt)
+	if err != nil {
+		return nil, err
+	}
+	if checkedInAt != nil {
+		return nil, ErrAlreadyCheckedIn
+	}
+
+	var registered bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM attendees WHERE event_id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		attendee.EventId, toUserId,
+	).Scan(&registered)
+	if err != nil {
+		return nil, err
+	}
+	if registered {
+		return nil, ErrAlreadyRegistered
+	}
+
+	transferred := Attendee{
+		EventId: attendee.EventId,
+		UserId:  toUserId,
+		Status:  attendee.Status,
+		OrderId: attendee.OrderId,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO attendees (event_id, user_id, status, order_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, transferred.EventId, transferred.UserId, transferred.Status, transferred.OrderId).Scan(&transferred.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range []string{"attendee_answers", "attendee_guests"} {
+		query := `UPDATE ` + table + ` SET attendee_id = $1 WHERE attendee_id = $2`
+		if _, err := tx.ExecContext(ctx, query, transferred.Id, attendee.Id); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE attendees SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1`, attendee.Id); err != nil {
+		return nil, err
+	}
+
+	entry.EventId = attendee.EventId
+	entry.OrderId = attendee.OrderId
+	entry.Action = TicketActionTransferred
+	entry.FromUserId = &attendee.UserId
+	entry.ToUserId = &toUserId
+	if err := insertTicketHistory(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &transferred, nil
+}
+
+func (m *TicketHistoryModel) queryHistory(query string, args ...interface{}) ([]*TicketHistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*TicketHistoryEntry, 0)
+	for rows.Next() {
+		var e TicketHistoryEntry
+		err := rows.Scan(
+			&e.Id,
+			&e.EventId,
+			&e.OrderId,
+			&e.Action,
+			&e.FromUserId,
+			&e.ToUserId,
+			&e.ActorId,
+			&e.RefundCents,
+			&e.RefundReference,
+			&e.Note,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+const ticketHistoryColumns = `id, event_id, order_id, action, from_user_id, to_user_id, actor_id, refund_cents,
+	COALESCE(refund_reference, ''), note, created_at`
+
+// ✅ GetByEvent — every cancellation, removal and transfer for an event
+func (m *TicketHistoryModel) GetByEvent(eventId int) ([]*TicketHistoryEntry, error) {
+	return m.queryHistory(`SELECT `+ticketHistoryColumns+` FROM ticket_history WHERE event_id = $1 ORDER BY id DESC`, eventId)
+}
+
+// ✅ GetByUser — changes to tickets the user gave up or received
+func (m *TicketHistoryModel) GetByUser(userId int) ([]*TicketHistoryEntry, error) {
+	return m.queryHistory(`
+		SELECT `+ticketHistoryColumns+`
+		FROM ticket_history
+		WHERE from_user_id = $1 OR to_user_id = $1
+		ORDER BY id DESC
+	`, userId)
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrTicketTypeInUse is returned when deleting a ticket type that has orders
+var ErrTicketTypeInUse = errors.New("ticket type has orders")
+
+type TicketTypeModel struct {
+	DB *sql.DB
+}
+
+type TicketType struct {
+	Id          int        `json:"Id"`
+	EventId     int        `json:"EventId"`
+	Name        string     `json:"Name"`
+	Description string     `json:"Description"`
+	PriceCents  int64      `json:"PriceCents"`
+	Currency    string     `json:"Currency"`
+	Quantity    int        `json:"Quantity"`
+	SalesStart  *time.Time `json:"SalesStart"`
+	SalesEnd    *time.Time `json:"SalesEnd"`
+	// Tickets in paid orders, and in pending orders that have not yet expired
+	Sold     int `json:"Sold"`
+	Reserved int `json:"Reserved"`
+}
+
+// Available is how many tickets can still be bought
+func (t *TicketType) Available() int {
+	if n := t.Quantity - t.Sold - t.Reserved; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// OnSale reports whether the sale window is open at the given time
+func (t *TicketType) OnSale(now time.Time) bool {
+	if t.SalesStart != nil && now.Before(*t.SalesStart) {
+		return false
+	}
+	if t.SalesEnd != nil && !now.Before(*t.SalesEnd) {
+		return false
+	}
+	return true
+}
+
+const ticketTypeSelect = `
+	SELECT t.id, t.event_id, t.name, t.description, t.price_cents, t.currency, t.quantity,
+		t.sales_start, t.sales_end,
+		COALESCE(SUM(oi.quantity) FILTER (WHERE o.status = 'paid'), 0),
+		COALESCE(SUM(oi.quantity) FILTER (WHERE o.status = 'pending' AND o.expires_at > NOW()), 0)
+	FROM ticket_types t
+	LEFT JOIN order_items oi ON oi.ticket_type_id = t.id
+	LEFT JOIN orders o ON o.id = oi.order_id
+`
+
+func scanTicketType(row rowScanner) (*TicketType, error) {
+	var t TicketType
+	err := row.Scan(
+		&t.Id,
+		&t.EventId,
+		&t.Name,
+		&t.Description,
+		&t.PriceCents,
+		&t.Currency,
+		&t.Quantity,
+		&t.SalesStart,
+		&t.SalesEnd,
+		&t.Sold,
+		&t.Reserved,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ✅ Insert — adds a ticket tier to an event
+func (m *TicketTypeModel) Insert(t *TicketType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO ticket_types (event_id, name, description, price_cents, currency, quantity, sales_start, sales_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err := m.DB.QueryRowContext(ctx, query,
+		t.EventId,
+		t.Name,
+		t.Description,
+		t.PriceCents,
+		t.Currency,
+		t.Quantity,
+		t.SalesStart,
+		t.SalesEnd,
+	).Scan(&t.Id)
+	return translateError(err)
+}
+
+// ✅ GetByEvent — an event's ticket tiers with sales counts
+func (m *TicketTypeModel) GetByEvent(eventId int) ([]*TicketType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := ticketTypeSelect + `
+		WHERE t.event_id = $1
+		GROUP BY t.id
+		ORDER BY t.price_cents, t.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make([]*TicketType, 0)
+	for rows.Next() {
+		t, err := scanTicketType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// ✅ Get — one ticket tier of an event
+func (m *TicketTypeModel) Get(eventId, id int) (*TicketType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := ticketTypeSelect + `
+		WHERE t.event_id = $1 AND t.id = $2
+		GROUP BY t.id
+	`
+	t, err := scanTicketType(m.DB.QueryRowContext(ctx, query, eventId, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// ✅ Update — changes a ticket tier's details
+func (m *TicketTypeModel) Update(t *TicketType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE ticket_types
+		SET name = $1, description = $2, price_cents = $3, currency = $4, quantity = $5, sales_start = $6, sales_end = $7
+		WHERE id = $8 AND event_id = $9
+	`
+	_, err := m.DB.ExecContext(ctx, query,
+		t.Name,
+		t.Description,
+		t.PriceCents,
+		t.Currency,
+		t.Quantity,
+		t.SalesStart,
+		t.SalesEnd,
+		t.Id,
+		t.EventId,
+	)
+	return translateError(err)
+}
+
+// ✅ Delete — removes a ticket tier that has never been ordered
+func (m *TicketTypeModel) Delete(eventId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var orders int
+	err := m.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM order_items WHERE ticket_type_id = $1`, id).Scan(&orders)
+	if err != nil {
+		return err
+	}
+	if orders > 0 {
+		return ErrTicketTypeInUse
+	}
+
+	_, err = m.DB.ExecContext(ctx, `DELETE FROM ticket_types WHERE id = $1 AND event_id = $2`, id, eventId)
+	return translateError(err)
+}
+
+// ✅ HasPaidTickets — whether registering for the event requires checkout
+func (m *TicketTypeModel) HasPaidTickets(eventId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM ticket_types WHERE event_id = $1 AND price_cents > 0)`,
+		eventId,
+	).Scan(&exists)
+	return exists, err
+}
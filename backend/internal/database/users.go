@@ -11,42 +11,103 @@ type UserModel struct {
 }
 
 type User struct {
-	Id       int    `json:"Id"`
-	Email    string `json:"Email"`
-	Name     string `json:"Name"`
-	Password string `json:"-"` // omit from JSON responses
+	Id            int    `json:"Id"`
+	Email         string `json:"Email"`
+	Name          string `json:"Name"`
+	Password      string `json:"-"` // omit from JSON responses
+	AvatarUrl     string `json:"AvatarUrl"`
+	Bio           string `json:"Bio"`
+	Timezone      string `json:"Timezone"`
+	EmailVerified bool   `json:"EmailVerified"`
+	PendingEmail  string `json:"PendingEmail,omitempty"`
+
+	DeletionMode        string     `json:"DeletionMode,omitempty"`
+	DeletionScheduledAt *time.Time `json:"DeletionScheduledAt,omitempty"`
+}
+
+const (
+	DeletionModeAnonymize = "anonymize"
+	DeletionModeCascade   = "cascade"
+)
+
+// PublicProfile is the subset of a user that anyone may see
+type PublicProfile struct {
+	Id        int    `json:"Id"`
+	Name      string `json:"Name"`
+	AvatarUrl string `json:"AvatarUrl"`
+	Bio       string `json:"Bio"`
 }
 
-func (m *UserModel) Insert(user *User) error {
+const userColumns = `id, email, password, name, avatar_url, bio, timezone, email_verified, COALESCE(pending_email, ''),
+	COALESCE(deletion_mode, ''), deletion_scheduled_at`
+
+// ✅ Insert — creates the user and adds them to the shared default
+// organization with the given role, in one transaction so a failed
+// membership leaves no account behind
+func (m *UserModel) Insert(user *User, role string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO users (email, name, password)
 		VALUES ($1, $2, $3)
 		RETURNING id
 	`
 
-	err := m.DB.QueryRowContext(ctx, query, user.Email, user.Name, user.Password).Scan(&user.Id)
+	err = tx.QueryRowContext(ctx, query, user.Email, user.Name, user.Password).Scan(&user.Id)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
-	return nil
+	query = `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		SELECT id, $1, $2 FROM organizations WHERE slug = $3
+	`
+	if _, err := tx.ExecContext(ctx, query, user.Id, role, DefaultOrganizationSlug); err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit()
 }
 
 
-func (m *UserModel) getUser(query string, args ...interface{}) (*User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanUser(row rowScanner) (*User, error) {
 	var user User
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+	err := row.Scan(
 		&user.Id,
 		&user.Email,
 		&user.Password,
 		&user.Name,
+		&user.AvatarUrl,
+		&user.Bio,
+		&user.Timezone,
+		&user.EmailVerified,
+		&user.PendingEmail,
+		&user.DeletionMode,
+		&user.DeletionScheduledAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (m *UserModel) getUser(query string, args ...interface{}) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	user, err := scanUser(m.DB.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -54,17 +115,349 @@ func (m *UserModel) getUser(query string, args ...interface{}) (*User, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (m *UserModel) Get(id int) (*User, error) {
 	// ✅ PostgreSQL-style placeholder
-	query := `SELECT id, email, password, name FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
 	return m.getUser(query, id)
 }
 
 func (m *UserModel) GetByEmail(email string) (*User, error) {
 	// ✅ PostgreSQL-style placeholder
-	query := `SELECT id, email, password, name FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
 	return m.getUser(query, email)
 }
+
+// ✅ Update — saves the editable profile fields
+func (m *UserModel) Update(user *User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET name = $1, avatar_url = $2, bio = $3, timezone = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+
+	_, err := m.DB.ExecContext(ctx, query,
+		user.Name,
+		user.AvatarUrl,
+		user.Bio,
+		user.Timezone,
+		user.Id,
+	)
+	return translateError(err)
+}
+
+// ✅ UpdatePassword — stores an already hashed password
+func (m *UserModel) UpdatePassword(id int, hashedPassword string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := m.DB.ExecContext(ctx, query, hashedPassword, id)
+	return err
+}
+
+// ✅ SetPendingEmail — parks a new address until the user confirms it
+func (m *UserModel) SetPendingEmail(id int, email, tokenHash string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET pending_email = $1, email_token_hash = $2, email_token_expires_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+	_, err := m.DB.ExecContext(ctx, query, email, tokenHash, expiresAt, id)
+	return translateError(err)
+}
+
+// ✅ ConfirmPendingEmail — swaps in the pending address if the token matches and has not expired.
+// Returns false when no user holds a valid token.
+func (m *UserModel) ConfirmPendingEmail(id int, tokenHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET email = pending_email,
+			email_verified = TRUE,
+			pending_email = NULL,
+			email_token_hash = NULL,
+			email_token_expires_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+			AND pending_email IS NOT NULL
+			AND email_token_hash = $2
+			AND email_token_expires_at > NOW()
+	`
+	result, err := m.DB.ExecContext(ctx, query, id, tokenHash)
+	if err != nil {
+		// Someone else may have taken the address since it was requested
+		return false, translateError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ✅ GetPublicProfile — returns only the fields safe to show other users
+func (m *UserModel) GetPublicProfile(id int) (*PublicProfile, error) {
+	user, err := m.Get(id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return &PublicProfile{
+		Id:        user.Id,
+		Name:      user.Name,
+		AvatarUrl: user.AvatarUrl,
+		Bio:       user.Bio,
+	}, nil
+}
+
+// ✅ ScheduleDeletion — marks the account for removal once the grace period ends
+func (m *UserModel) ScheduleDeletion(id int, mode string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE users SET deletion_mode = $1, deletion_scheduled_at = $2 WHERE id = $3`
+	_, err := m.DB.ExecContext(ctx, query, mode, at, id)
+	return err
+}
+
+// ✅ CancelDeletion — clears a pending deletion request
+func (m *UserModel) CancelDeletion(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE users SET deletion_mode = NULL, deletion_scheduled_at = NULL WHERE id = $1`
+	_, err := m.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// ✅ GetDueForDeletion — users whose grace period has run out
+func (m *UserModel) GetDueForDeletion() ([]*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE deletion_scheduled_at <= NOW() AND deleted_at IS NULL`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ✅ Anonymize — strips personal data but keeps the row so owned events
+// survive. Registrations, memberships and staff roles go, and open orders
+// are cancelled. Returns ErrLastOwner while the user is the only owner of
+// an organization, which would otherwise be left without one.
+func (m *UserModel) Anonymize(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the owners of the user's organizations so none steps down meanwhile
+	_, err = tx.ExecContext(ctx, `
+		SELECT 1 FROM organization_members
+		WHERE role = 'owner'
+			AND organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = $1 AND role = 'owner')
+		FOR UPDATE
+	`, id)
+	if err != nil {
+		return err
+	}
+	var lastOwner bool
+	if err := tx.QueryRowContext(ctx, ownsAloneQuery, id).Scan(&lastOwner); err != nil {
+		return err
+	}
+	if lastOwner {
+		return ErrLastOwner
+	}
+
+	query := `
+		UPDATE users
+		SET email = 'deleted-user-' || id || '@deleted.invalid',
+			name = 'Deleted user',
+			password = '',
+			avatar_url = '',
+			bio = '',
+			email_verified = FALSE,
+			pending_email = NULL,
+			email_token_hash = NULL,
+			email_token_expires_at = NULL,
+			deletion_mode = NULL,
+			deletion_scheduled_at = NULL,
+			anonymized_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM attendees WHERE user_id = $1`,
+		`DELETE FROM organization_members WHERE user_id = $1`,
+		`DELETE FROM event_staff WHERE user_id = $1`,
+		`UPDATE orders SET status = 'cancelled' WHERE user_id = $1 AND status = 'pending'`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ✅ Delete — soft-deletes the user along with their owned events and
+// attendances; all of it is removed for good by Purge
+func (m *UserModel) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := []string{
+		`UPDATE users SET deleted_at = CURRENT_TIMESTAMP, deletion_mode = NULL, deletion_scheduled_at = NULL WHERE id = $1`,
+		`UPDATE events SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $1, version = version + 1 WHERE owner_id = $1 AND deleted_at IS NULL`,
+		`UPDATE attendees SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND deleted_at IS NULL`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeletedUser is an account waiting in the trash
+type DeletedUser struct {
+	Id        int       `json:"Id"`
+	Name      string    `json:"Name"`
+	Email     string    `json:"Email"`
+	DeletedAt time.Time `json:"DeletedAt"`
+}
+
+// ✅ GetDeletedInOrganization — deleted accounts of an organization's members
+func (m *UserModel) GetDeletedInOrganization(orgId int) ([]*DeletedUser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT u.id, u.name, u.email, u.deleted_at
+		FROM users u
+		JOIN organization_members om ON om.user_id = u.id
+		WHERE om.organization_id = $1 AND u.deleted_at IS NOT NULL
+		ORDER BY u.deleted_at DESC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, orgId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*DeletedUser, 0)
+	for rows.Next() {
+		var user DeletedUser
+		if err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.DeletedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ✅ Restore — takes a member's account back out of the trash. The events
+// and registrations deleted with it stay in their own trash so each can be
+// checked on the way back. Returns false if the account is not in the
+// organization's trash, and ErrDuplicate if its email has been taken since.
+func (m *UserModel) Restore(orgId, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+			AND EXISTS (SELECT 1 FROM organization_members WHERE organization_id = $2 AND user_id = $1)
+	`
+
+	result, err := m.DB.ExecContext(ctx, query, id, orgId)
+	if err != nil {
+		return false, translateError(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ✅ Purge — permanently removes users deleted before the cutoff; their
+// owned events and attendances cascade. Orders for those events are removed
+// first, as they would keep the events' ticket types and promo codes alive.
+func (m *UserModel) Purge(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM orders
+		WHERE event_id IN (
+			SELECT e.id FROM events e JOIN users u ON u.id = e.owner_id WHERE u.deleted_at < $1
+		)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,395 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrVenueInUse is returned when deleting a venue upcoming events are held at
+	ErrVenueInUse = errors.New("venue has upcoming events")
+	// ErrRoomInUse is returned when deleting a room upcoming events have booked
+	ErrRoomInUse = errors.New("room has upcoming events")
+)
+
+// DefaultEventDuration is how long an event without an end time holds its room
+const DefaultEventDuration = 2 * time.Hour
+
+// bookingRange is the time an event (aliased e) holds its room for. It must
+// match the events_room_booking_excl constraint.
+const bookingRange = `tsrange(e.datetime, COALESCE(e.ends_at, e.datetime + INTERVAL '2 hours'))`
+
+// Events that still need their venue: not cancelled, deleted or over
+const upcomingBooking = `e.deleted_at IS NULL AND e.status NOT IN ('cancelled', 'completed')
+	AND COALESCE(e.ends_at, e.datetime + INTERVAL '2 hours') > NOW()`
+
+type VenueModel struct {
+	DB *sql.DB
+}
+
+type Venue struct {
+	Id             int      `json:"Id"`
+	OrganizationId int      `json:"OrganizationId"`
+	Name           string   `json:"Name"`
+	Address        Address  `json:"Address"`
+	Latitude       *float64 `json:"Latitude"`
+	Longitude      *float64 `json:"Longitude"`
+	// People the whole venue holds; nil means unknown
+	Capacity      *int          `json:"Capacity"`
+	Accessibility Accessibility `json:"Accessibility"`
+	CreatedBy     *int          `json:"CreatedBy"`
+	CreatedAt     time.Time     `json:"CreatedAt"`
+	UpdatedAt     time.Time     `json:"UpdatedAt"`
+	// Filled in when fetching a single venue
+	Rooms []*Room `json:"Rooms,omitempty"`
+}
+
+// Accessibility describes how easily a venue can be reached and used
+type Accessibility struct {
+	StepFreeAccess    bool   `json:"StepFreeAccess"`
+	AccessibleToilets bool   `json:"AccessibleToilets"`
+	HearingLoop       bool   `json:"HearingLoop"`
+	Notes             string `json:"Notes"`
+}
+
+// Room is a bookable space inside a venue
+type Room struct {
+	Id      int    `json:"Id"`
+	VenueId int    `json:"VenueId"`
+	Name    string `json:"Name"`
+	// nil means unknown
+	Capacity       *int   `json:"Capacity"`
+	Floor          string `json:"Floor"`
+	StepFreeAccess bool   `json:"StepFreeAccess"`
+}
+
+// RoomBooking is the span an event holds a room for. The event's name is
+// left out when the viewer may not see the event.
+type RoomBooking struct {
+	EventId   int       `json:"EventId"`
+	EventName string    `json:"EventName,omitempty"`
+	RoomId    int       `json:"RoomId"`
+	RoomName  string    `json:"RoomName"`
+	StartsAt  time.Time `json:"StartsAt"`
+	EndsAt    time.Time `json:"EndsAt"`
+}
+
+const venueColumns = `v.id, v.organization_id, v.name,
+	COALESCE(v.street, ''), COALESCE(v.city, ''), COALESCE(v.region, ''), COALESCE(v.postal_code, ''), COALESCE(v.country, ''),
+	v.latitude, v.longitude, v.capacity, v.step_free_access, v.accessible_toilets, v.hearing_loop, v.accessibility_notes,
+	v.created_by, v.created_at, v.updated_at`
+
+func scanVenue(row rowScanner) (*Venue, error) {
+	var v Venue
+	err := row.Scan(
+		&v.Id,
+		&v.OrganizationId,
+		&v.Name,
+		&v.Address.Street,
+		&v.Address.City,
+		&v.Address.Region,
+		&v.Address.PostalCode,
+		&v.Address.Country,
+		&v.Latitude,
+		&v.Longitude,
+		&v.Capacity,
+		&v.Accessibility.StepFreeAccess,
+		&v.Accessibility.AccessibleToilets,
+		&v.Accessibility.HearingLoop,
+		&v.Accessibility.Notes,
+		&v.CreatedBy,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ✅ Insert — saves a venue for the organization
+func (m *VenueModel) Insert(v *Venue) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO venues (organization_id, name, street, city, region, postal_code, country, latitude, longitude,
+			capacity, step_free_access, accessible_toilets, hearing_loop, accessibility_notes, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9,
+			$10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at, updated_at
+	`
+	err := m.DB.QueryRowContext(ctx, query,
+		v.OrganizationId,
+		v.Name,
+		v.Address.Street,
+		v.Address.City,
+		v.Address.Region,
+		v.Address.PostalCode,
+		v.Address.Country,
+		v.Latitude,
+		v.Longitude,
+		v.Capacity,
+		v.Accessibility.StepFreeAccess,
+		v.Accessibility.AccessibleToilets,
+		v.Accessibility.HearingLoop,
+		v.Accessibility.Notes,
+		v.CreatedBy,
+	).Scan(&v.Id, &v.CreatedAt, &v.UpdatedAt)
+	return translateError(err)
+}
+
+// ✅ GetByOrganization — the organization's venues by name, without rooms
+func (m *VenueModel) GetByOrganization(orgId int) ([]*Venue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + venueColumns + ` FROM venues v WHERE v.organization_id = $1 ORDER BY v.name`
+	rows, err := m.DB.QueryContext(ctx, query, orgId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	venues := make([]*Venue, 0)
+	for rows.Next() {
+		v, err := scanVenue(rows)
+		if err != nil {
+			return nil, err
+		}
+		venues = append(venues, v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return venues, nil
+}
+
+// ✅ Get — one venue of the organization with its rooms
+func (m *VenueModel) Get(orgId, id int) (*Venue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + venueColumns + ` FROM venues v WHERE v.id = $1 AND v.organization_id = $2`
+	v, err := scanVenue(m.DB.QueryRowContext(ctx, query, id, orgId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	query = `
+		SELECT id, venue_id, name, capacity, floor, step_free_access
+		FROM venue_rooms
+		WHERE venue_id = $1
+		ORDER BY name
+	`
+	rows, err := m.DB.QueryContext(ctx, query, v.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	v.Rooms = make([]*Room, 0)
+	for rows.Next() {
+		var r Room
+		if err := rows.Scan(&r.Id, &r.VenueId, &r.Name, &r.Capacity, &r.Floor, &r.StepFreeAccess); err != nil {
+			return nil, err
+		}
+		v.Rooms = append(v.Rooms, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ✅ Update — changes a venue's details. Events keep the location they
+// copied when they were saved.
+func (m *VenueModel) Update(v *Venue) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE venues
+		SET name = $1, street = NULLIF($2, ''), city = NULLIF($3, ''), region = NULLIF($4, ''),
+			postal_code = NULLIF($5, ''), country = NULLIF($6, ''), latitude = $7, longitude = $8, capacity = $9,
+			step_free_access = $10, accessible_toilets = $11, hearing_loop = $12, accessibility_notes = $13,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $14 AND organization_id = $15
+		RETURNING updated_at
+	`
+	err := m.DB.QueryRowContext(ctx, query,
+		v.Name,
+		v.Address.Street,
+		v.Address.City,
+		v.Address.Region,
+		v.Address.PostalCode,
+		v.Address.Country,
+		v.Latitude,
+		v.Longitude,
+		v.Capacity,
+		v.Accessibility.StepFreeAccess,
+		v.Accessibility.AccessibleToilets,
+		v.Accessibility.HearingLoop,
+		v.Accessibility.Notes,
+		v.Id,
+		v.OrganizationId,
+	).Scan(&v.UpdatedAt)
+	return translateError(err)
+}
+
+// ✅ Delete — removes a venue no upcoming event is held at. Past events
+// keep their copied location and lose the link.
+func (m *VenueModel) Delete(orgId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inUse bool
+	err := m.DB.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM events e WHERE e.venue_id = $1 AND `+upcomingBooking+`)`,
+		id,
+	).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrVenueInUse
+	}
+
+	_, err = m.DB.ExecContext(ctx, `DELETE FROM venues WHERE id = $1 AND organization_id = $2`, id, orgId)
+	return err
+}
+
+// ✅ InsertRoom — adds a room to a venue
+func (m *VenueModel) InsertRoom(r *Room) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO venue_rooms (venue_id, name, capacity, floor, step_free_access)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := m.DB.QueryRowContext(ctx, query, r.VenueId, r.Name, r.Capacity, r.Floor, r.StepFreeAccess).Scan(&r.Id)
+	return translateError(err)
+}
+
+// ✅ GetRoom — one room of a venue
+func (m *VenueModel) GetRoom(venueId, id int) (*Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, venue_id, name, capacity, floor, step_free_access
+		FROM venue_rooms
+		WHERE id = $1 AND venue_id = $2
+	`
+	var r Room
+	err := m.DB.QueryRowContext(ctx, query, id, venueId).
+		Scan(&r.Id, &r.VenueId, &r.Name, &r.Capacity, &r.Floor, &r.StepFreeAccess)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ✅ UpdateRoom — changes a room's details
+func (m *VenueModel) UpdateRoom(r *Room) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE venue_rooms
+		SET name = $1, capacity = $2, floor = $3, step_free_access = $4
+		WHERE id = $5 AND venue_id = $6
+	`
+	_, err := m.DB.ExecContext(ctx, query, r.Name, r.Capacity, r.Floor, r.StepFreeAccess, r.Id, r.VenueId)
+	return translateError(err)
+}
+
+// ✅ DeleteRoom — removes a room no upcoming event has booked
+func (m *VenueModel) DeleteRoom(venueId, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inUse bool
+	err := m.DB.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM events e WHERE e.room_id = $1 AND `+upcomingBooking+`)`,
+		id,
+	).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrRoomInUse
+	}
+
+	_, err = m.DB.ExecContext(ctx, `DELETE FROM venue_rooms WHERE id = $1 AND venue_id = $2`, id, venueId)
+	return err
+}
+
+func queryBookings(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*RoomBooking, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := make([]*RoomBooking, 0)
+	for rows.Next() {
+		var b RoomBooking
+		if err := rows.Scan(&b.EventId, &b.EventName, &b.RoomId, &b.RoomName, &b.StartsAt, &b.EndsAt); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, &b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// ✅ GetBookings — when the venue's rooms are taken between from and to,
+// as seen by one viewer
+func (m *VenueModel) GetBookings(venueId, viewerId int, from, to time.Time, all bool) ([]*RoomBooking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT e.id, CASE WHEN $5 OR ` + visibleTo + ` THEN e.name ELSE '' END, r.id, r.name,
+			lower(` + bookingRange + `), upper(` + bookingRange + `)
+		FROM events e
+		JOIN venue_rooms r ON r.id = e.room_id
+		WHERE r.venue_id = $2 AND e.deleted_at IS NULL AND e.status <> 'cancelled'
+			AND ` + bookingRange + ` && tsrange($3, $4)
+		ORDER BY e.datetime, r.name
+	`
+	return queryBookings(ctx, m.DB, query, viewerId, venueId, from, to, all)
+}
+
+// ✅ GetRoomConflicts — live events holding the room at any time between
+// start and end, other than the event being saved
+func (m *VenueModel) GetRoomConflicts(roomId, excludeEventId int, start string, end *time.Time) ([]*RoomBooking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT e.id, e.name, r.id, r.name, lower(` + bookingRange + `), upper(` + bookingRange + `)
+		FROM events e
+		JOIN venue_rooms r ON r.id = e.room_id
+		WHERE e.room_id = $1 AND e.id <> $2 AND e.deleted_at IS NULL AND e.status <> 'cancelled'
+			AND ` + bookingRange + ` && tsrange($3::timestamp, COALESCE($4::timestamp, $3::timestamp + INTERVAL '2 hours'))
+		ORDER BY e.datetime
+	`
+	return queryBookings(ctx, m.DB, query, roomId, excludeEventId, start, end)
+}
@@ -0,0 +1,24 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when no place matches a query
+var ErrNotFound = errors.New("place not found")
+
+// Place is a geocoding result
+type Place struct {
+	Name        string  `json:"Name"`
+	Region      string  `json:"Region"`
+	CountryCode string  `json:"CountryCode"`
+	Country     string  `json:"Country"`
+	Latitude    float64 `json:"Latitude"`
+	Longitude   float64 `json:"Longitude"`
+}
+
+// Geocoder turns a free-text location or address into coordinates
+type Geocoder interface {
+	Geocode(ctx context.Context, query string) (*Place, error)
+}
@@ -0,0 +1,178 @@
+package geocode
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// places.csv lists major cities with their alternate names, region, country
+// and population, enough to place most events without a network lookup
+//
+//go:embed places.csv
+var placesCSV string
+
+// Longest place name, in words, tried against a query
+const maxNameWords = 4
+
+// Country spellings beyond the names in places.csv
+var countryAliases = map[string]string{
+	"usa":                      "US",
+	"america":                  "US",
+	"united states of america": "US",
+	"uk":                       "GB",
+	"great britain":            "GB",
+	"england":                  "GB",
+	"scotland":                 "GB",
+	"wales":                    "GB",
+	"holland":                  "NL",
+	"czech republic":           "CZ",
+	"deutschland":              "DE",
+	"espana":                   "ES",
+	"italia":                   "IT",
+	"schweiz":                  "CH",
+	"suisse":                   "CH",
+	"osterreich":               "AT",
+	"uae":                      "AE",
+}
+
+type offlinePlace struct {
+	Place
+	population int
+}
+
+// OfflineGeocoder matches city names in a query against the bundled places
+// dataset. It only knows cities, so every address resolves to its city's
+// centre.
+type OfflineGeocoder struct {
+	// Normalized names and alternate names to the places carrying them
+	names map[string][]*offlinePlace
+	// Normalized country names to country codes
+	countries map[string]string
+}
+
+// NewOfflineGeocoder loads the bundled dataset
+func NewOfflineGeocoder() (*OfflineGeocoder, error) {
+	records, err := csv.NewReader(strings.NewReader(placesCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("geocode: reading places: %w", err)
+	}
+
+	g := &OfflineGeocoder{
+		names:     make(map[string][]*offlinePlace),
+		countries: make(map[string]string),
+	}
+	for alias, code := range countryAliases {
+		g.countries[normalize(alias)] = code
+	}
+	// The first record is the header
+	for i, r := range records[1:] {
+		if len(r) != 8 {
+			return nil, fmt.Errorf("geocode: places line %d: expected 8 fields, got %d", i+2, len(r))
+		}
+		lat, errLat := strconv.ParseFloat(r[5], 64)
+		lng, errLng := strconv.ParseFloat(r[6], 64)
+		population, errPop := strconv.Atoi(r[7])
+		if errLat != nil || errLng != nil || errPop != nil {
+			return nil, fmt.Errorf("geocode: places line %d: invalid number", i+2)
+		}
+
+		p := &offlinePlace{
+			Place: Place{
+				Name:        r[0],
+				Region:      r[2],
+				CountryCode: r[3],
+				Country:     r[4],
+				Latitude:    lat,
+				Longitude:   lng,
+			},
+			population: population,
+		}
+		names := []string{r[0]}
+		if r[1] != "" {
+			names = append(names, strings.Split(r[1], "|")...)
+		}
+		for _, name := range names {
+			key := normalize(name)
+			g.names[key] = append(g.names[key], p)
+		}
+		g.countries[normalize(p.Country)] = p.CountryCode
+	}
+	return g, nil
+}
+
+// Geocode finds the city a query mentions. When several match, a country
+// or region named in the query decides, then the longer name, then the
+// larger city.
+func (g *OfflineGeocoder) Geocode(ctx context.Context, query string) (*Place, error) {
+	words := strings.Fields(normalize(query))
+
+	// Countries may be named in full anywhere, or by code as a whole segment
+	countries := make(map[string]bool)
+	for _, segment := range strings.Split(query, ",") {
+		segment = strings.ToUpper(strings.TrimSpace(segment))
+		if len(segment) == 2 {
+			for _, code := range g.countries {
+				if code == segment {
+					countries[code] = true
+				}
+			}
+		}
+	}
+	mentioned := make(map[string]bool)
+	for n := maxNameWords; n >= 1; n-- {
+		for i := 0; i+n <= len(words); i++ {
+			phrase := strings.Join(words[i:i+n], " ")
+			if code, ok := g.countries[phrase]; ok {
+				countries[code] = true
+			}
+			mentioned[phrase] = true
+		}
+	}
+
+	var best *offlinePlace
+	bestScore := -1
+	for phrase := range mentioned {
+		for _, p := range g.names[phrase] {
+			score := len(strings.Fields(phrase))
+			if countries[p.CountryCode] {
+				score += 100
+			}
+			if mentioned[normalize(p.Region)] && normalize(p.Region) != phrase {
+				score += 10
+			}
+			if score > bestScore || (score == bestScore && p.population > best.population) {
+				best, bestScore = p, score
+			}
+		}
+	}
+	if best == nil {
+		return nil, ErrNotFound
+	}
+	place := best.Place
+	return &place, nil
+}
+
+// normalize lowercases s, strips accents and turns punctuation into spaces
+// so "Düsseldorf," and "dusseldorf" compare equal
+func normalize(s string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err == nil {
+		s = stripped
+	}
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,294 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to values decoded by encoding/json.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a test operation does not match the document
+var ErrTestFailed = errors.New("test operation failed")
+
+// Error describes a JSON Patch operation that could not be applied
+type Error struct {
+	Index  int
+	Op     string
+	Path   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d (%s %s) %s", e.Index, e.Op, e.Path, e.Reason)
+}
+
+// Operation is one step of a JSON Patch document
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+	// Whether "value" was present at all, since null is a valid value
+	HasValue bool
+}
+
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, target := range map[string]*string{"op": &o.Op, "path": &o.Path, "from": &o.From} {
+		if raw, ok := fields[name]; ok {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return fmt.Errorf("%s must be a string", name)
+			}
+		}
+	}
+	if raw, ok := fields["value"]; ok {
+		o.HasValue = true
+		return json.Unmarshal(raw, &o.Value)
+	}
+	return nil
+}
+
+// MergePatch applies an RFC 7396 merge patch. Objects are merged key by key,
+// null removes a key and anything else replaces the target outright. The
+// target is not modified.
+func MergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	merged := make(map[string]interface{})
+	if t, ok := target.(map[string]interface{}); ok {
+		for k, v := range t {
+			merged[k] = v
+		}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = MergePatch(merged[k], v)
+	}
+	return merged
+}
+
+// Apply runs an RFC 6902 patch against doc and returns the result. The
+// operations apply atomically: on error doc is left as it was.
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = deepCopy(doc)
+	for i, op := range ops {
+		fail := func(reason string) error {
+			return &Error{Index: i, Op: op.Op, Path: op.Path, Reason: reason}
+		}
+
+		path, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		if (op.Op == "add" || op.Op == "replace" || op.Op == "test") && !op.HasValue {
+			return nil, fail("is missing a value")
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = add(doc, path, deepCopy(op.Value))
+		case "remove":
+			doc, err = remove(doc, path)
+		case "replace":
+			if _, err = get(doc, path); err == nil {
+				doc, err = set(doc, path, deepCopy(op.Value))
+			}
+		case "move", "copy":
+			var from []string
+			if from, err = parsePointer(op.From); err != nil {
+				break
+			}
+			if op.Op == "move" && len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+				return nil, fail("cannot move a value into itself")
+			}
+			var value interface{}
+			if value, err = get(doc, from); err != nil {
+				break
+			}
+			if op.Op == "move" {
+				if doc, err = remove(doc, from); err != nil {
+					break
+				}
+			} else {
+				value = deepCopy(value)
+			}
+			doc, err = add(doc, path, value)
+		case "test":
+			var value interface{}
+			if value, err = get(doc, path); err == nil && !reflect.DeepEqual(value, op.Value) {
+				return nil, ErrTestFailed
+			}
+		default:
+			return nil, fail("is not a known operation")
+		}
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// index resolves an array index token. "-" (one past the end) is only
+// allowed when appending.
+func index(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	limit := length - 1
+	if appending {
+		limit = length
+	}
+	if i > limit {
+		return 0, fmt.Errorf("index %d is out of range", i)
+	}
+	return i, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot look up %q in a scalar", token)
+		}
+	}
+	return doc, nil
+}
+
+// set stores value at an existing location, returning the possibly new root
+func set(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[key] = value
+	case []interface{}:
+		i, err := index(key, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	default:
+		return nil, fmt.Errorf("cannot set %q on a scalar", key)
+	}
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parentPath, key := path[:len(path)-1], path[len(path)-1]
+	parent, err := get(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[key] = value
+		return doc, nil
+	case []interface{}:
+		i, err := index(key, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		grown := make([]interface{}, 0, len(node)+1)
+		grown = append(append(append(grown, node[:i]...), value), node[i:]...)
+		return set(doc, parentPath, grown)
+	}
+	return nil, fmt.Errorf("cannot add %q to a scalar", key)
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	parentPath, key := path[:len(path)-1], path[len(path)-1]
+	parent, err := get(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[key]; !ok {
+			return nil, fmt.Errorf("member %q does not exist", key)
+		}
+		delete(node, key)
+		return doc, nil
+	case []interface{}:
+		i, err := index(key, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		shrunk := append(append(make([]interface{}, 0, len(node)-1), node[:i]...), node[i+1:]...)
+		return set(doc, parentPath, shrunk)
+	}
+	return nil, fmt.Errorf("cannot remove %q from a scalar", key)
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			copied[k] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(node))
+		for i, child := range node {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	}
+	return v
+}
@@ -0,0 +1,19 @@
+package mailer
+
+import (
+	"log"
+)
+
+// Mailer sends transactional email
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// LogMailer writes messages to the server log instead of delivering them.
+// It is the default until an SMTP provider is configured.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("📧 To: %s | Subject: %s\n%s\n", to, subject, body)
+	return nil
+}
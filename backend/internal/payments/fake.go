@@ -0,0 +1,202 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	FakeOutcomeManual  = "manual"
+	FakeOutcomeSucceed = "succeed"
+	FakeOutcomeFail    = "fail"
+)
+
+// Webhook attempts the fake makes before giving up on an automatic outcome
+const fakeDeliveryAttempts = 5
+
+// FakeProvider is an in-process gateway for local development and tests.
+// Payments settle only through a webhook: one the fake posts to WebhookURL
+// by itself according to Outcome, or one built with Simulate.
+type FakeProvider struct {
+	// Outcome decides what happens to new payments: succeed, fail or manual
+	Outcome string
+	Secret  string
+	// Where automatic outcomes are posted, like a real gateway would
+	WebhookURL string
+	// Gives checkout time to store the payment reference before it settles
+	SettleDelay time.Duration
+
+	mu        sync.Mutex
+	next      int
+	payments  map[string]PaymentRequest
+	cancelled map[string]bool
+	refunds   map[string]string
+}
+
+// NewFakeProvider creates a fake gateway. Without a secret it picks a random
+// one, so only the process itself can sign webhooks.
+func NewFakeProvider(outcome, secret, webhookURL string) (*FakeProvider, error) {
+	if outcome == "" {
+		outcome = FakeOutcomeManual
+	}
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+	return &FakeProvider{
+		Outcome:     outcome,
+		Secret:      secret,
+		WebhookURL:  webhookURL,
+		SettleDelay: time.Second,
+		payments:    map[string]PaymentRequest{},
+		cancelled:   map[string]bool{},
+		refunds:     map[string]string{},
+	}, nil
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	reference := fmt.Sprintf("fake_pay_%d_%d", req.OrderId, p.next)
+	p.payments[reference] = req
+	if p.Outcome != FakeOutcomeManual && p.WebhookURL != "" {
+		go p.settle(reference, p.Outcome)
+	}
+	return &Payment{Reference: reference, ClientSecret: reference + "_secret"}, nil
+}
+
+// settle posts the webhook for an automatic outcome, retrying like a real
+// gateway while the endpoint fails
+func (p *FakeProvider) settle(reference, outcome string) {
+	delay := p.SettleDelay
+	for attempt := 1; attempt <= fakeDeliveryAttempts; attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+
+		req, err := p.Simulate(p.WebhookURL, reference, outcome)
+		if err != nil {
+			log.Printf("❌ fake: could not build webhook for %s: %v\n", reference, err)
+			return
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < http.StatusMultipleChoices {
+				return
+			}
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		}
+		log.Printf("⚠️ fake: webhook for %s failed (attempt %d): %v\n", reference, attempt, err)
+	}
+}
+
+func (p *FakeProvider) CancelPayment(ctx context.Context, reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.payments[reference]; !ok {
+		return fmt.Errorf("fake: unknown payment %s", reference)
+	}
+	p.cancelled[reference] = true
+	return nil
+}
+
+// Refund always succeeds; payments made before a restart are not remembered,
+// so they are not checked
+func (p *FakeProvider) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.refunds[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return id, nil
+	}
+	p.next++
+	id := fmt.Sprintf("fake_refund_%d", p.next)
+	p.refunds[req.IdempotencyKey] = id
+	return id, nil
+}
+
+type fakeWebhook struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Simulate builds the signed webhook request the fake gateway would send for
+// a payment. Posting it to the webhook endpoint drives the normal flow.
+func (p *FakeProvider) Simulate(url, reference, outcome string) (*http.Request, error) {
+	event := fakeWebhook{Type: EventPaymentSucceeded, Reference: reference}
+	if outcome == FakeOutcomeFail {
+		event.Type = EventPaymentFailed
+		event.Message = "Your card was declined."
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Fake-Signature", p.sign(body))
+	return req, nil
+}
+
+func (p *FakeProvider) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	sig, err := hex.DecodeString(r.Header.Get("X-Fake-Signature"))
+	if err != nil || !hmac.Equal(sig, p.mac(body)) {
+		return nil, ErrInvalidWebhook
+	}
+
+	var event fakeWebhook
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+
+	p.mu.Lock()
+	_, known := p.payments[event.Reference]
+	cancelled := p.cancelled[event.Reference]
+	p.mu.Unlock()
+	if !known {
+		return nil, ErrInvalidWebhook
+	}
+	if cancelled {
+		event.Type = EventPaymentFailed
+		event.Message = "Payment was cancelled."
+	}
+
+	return &WebhookEvent{Type: event.Type, Reference: event.Reference, Message: event.Message}, nil
+}
+
+func (p *FakeProvider) mac(body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(p.Secret))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func (p *FakeProvider) sign(body []byte) string {
+	return hex.EncodeToString(p.mac(body))
+}
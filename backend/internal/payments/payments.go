@@ -0,0 +1,58 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrInvalidWebhook is returned when a webhook cannot be authenticated or parsed
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+)
+
+// PaymentRequest is what the checkout asks a provider to collect
+type PaymentRequest struct {
+	OrderId       int
+	AmountCents   int64
+	Currency      string
+	Description   string
+	CustomerEmail string
+}
+
+// RefundRequest returns part or all of a settled payment
+type RefundRequest struct {
+	Reference   string
+	AmountCents int64
+	// Makes retries of the same refund safe; unique per refund
+	IdempotencyKey string
+}
+
+// Payment is the provider's handle on a pending charge
+type Payment struct {
+	Reference string `json:"Reference"`
+	// Handed to the client to finish payment, e.g. a Stripe client secret
+	ClientSecret string `json:"ClientSecret,omitempty"`
+	// Hosted page to redirect the buyer to, when the provider has one
+	CheckoutUrl string `json:"CheckoutUrl,omitempty"`
+}
+
+// WebhookEvent is a provider notification normalized to our vocabulary
+type WebhookEvent struct {
+	Type      string
+	Reference string
+	Message   string
+}
+
+// Provider is a payment gateway
+type Provider interface {
+	Name() string
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error)
+	CancelPayment(ctx context.Context, reference string) error
+	// Refund returns money from a settled payment and gives the refund's reference
+	Refund(ctx context.Context, req RefundRequest) (string, error)
+	ParseWebhook(r *http.Request) (*WebhookEvent, error)
+}
@@ -0,0 +1,205 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripeAPIBase          = "https://api.stripe.com/v1"
+	stripeWebhookTolerance = 5 * time.Minute
+	maxWebhookBody         = 64 << 10
+)
+
+// StripeProvider talks to the Stripe PaymentIntents API over plain HTTP
+type StripeProvider struct {
+	SecretKey     string
+	WebhookSecret string
+	Client        *http.Client
+}
+
+func NewStripeProvider(secretKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{
+		SecretKey:     secretKey,
+		WebhookSecret: webhookSecret,
+		Client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *StripeProvider) Name() string { return "stripe" }
+
+type stripePaymentIntent struct {
+	Id           string `json:"id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type stripeError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *StripeProvider) do(ctx context.Context, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.SecretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var se stripeError
+		if json.Unmarshal(body, &se) == nil && se.Error.Message != "" {
+			return fmt.Errorf("stripe: %s", se.Error.Message)
+		}
+		return fmt.Errorf("stripe: unexpected status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (p *StripeProvider) CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.AmountCents, 10))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("description", req.Description)
+	form.Set("metadata[order_id]", strconv.Itoa(req.OrderId))
+	if req.CustomerEmail != "" {
+		form.Set("receipt_email", req.CustomerEmail)
+	}
+
+	var intent stripePaymentIntent
+	// Retried checkouts for the same order must not create a second charge
+	if err := p.do(ctx, "/payment_intents", form, "order-"+strconv.Itoa(req.OrderId), &intent); err != nil {
+		return nil, err
+	}
+	return &Payment{Reference: intent.Id, ClientSecret: intent.ClientSecret}, nil
+}
+
+func (p *StripeProvider) CancelPayment(ctx context.Context, reference string) error {
+	return p.do(ctx, "/payment_intents/"+url.PathEscape(reference)+"/cancel", url.Values{}, "", nil)
+}
+
+type stripeRefund struct {
+	Id string `json:"id"`
+}
+
+func (p *StripeProvider) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.Reference)
+	form.Set("amount", strconv.FormatInt(req.AmountCents, 10))
+
+	var refund stripeRefund
+	if err := p.do(ctx, "/refunds", form, req.IdempotencyKey, &refund); err != nil {
+		return "", err
+	}
+	return refund.Id, nil
+}
+
+type stripeEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			Id               string `json:"id"`
+			LastPaymentError *struct {
+				Message string `json:"message"`
+			} `json:"last_payment_error"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// ParseWebhook verifies the Stripe-Signature header and maps the event.
+// Event types we do not act on come back with an empty Type.
+func (p *StripeProvider) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	if err := p.verifySignature(r.Header.Get("Stripe-Signature"), body, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+
+	result := &WebhookEvent{Reference: event.Data.Object.Id}
+	switch event.Type {
+	case "payment_intent.succeeded":
+		result.Type = EventPaymentSucceeded
+	case "payment_intent.payment_failed", "payment_intent.canceled":
+		result.Type = EventPaymentFailed
+		if e := event.Data.Object.LastPaymentError; e != nil {
+			result.Message = e.Message
+		}
+	}
+	return result, nil
+}
+
+// verifySignature implements Stripe's scheme: v1 = HMAC-SHA256(secret, "t.body")
+func (p *StripeProvider) verifySignature(header string, body []byte, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidWebhook
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhook
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > stripeWebhookTolerance || age < -stripeWebhookTolerance {
+		return ErrInvalidWebhook
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhook
+}
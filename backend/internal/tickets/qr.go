@@ -0,0 +1,36 @@
+package tickets
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodePNG renders a code as a PNG of roughly size×size pixels
+func QRCodePNG(code string, size int) ([]byte, error) {
+	return qrcode.Encode(code, qrcode.Medium, size)
+}
+
+// QRCodeSVG renders a code as an SVG, one rect per dark module
+func QRCodeSVG(code string) ([]byte, error) {
+	qr, err := qrcode.New(code, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := qr.Bitmap()
+	n := len(bitmap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="1" height="1"/>`, x, y)
+			}
+		}
+	}
+	b.WriteString(`</svg>`)
+	return []byte(b.String()), nil
+}
@@ -45,7 +45,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
       });
     } catch (error: any) {
       const errorMessage =
-        error.response?.data?.error || "Login failed. Please try again.";
+        error.response?.data?.detail || "Login failed. Please try again.";
       toast.error("Login Failed", {
         description: errorMessage,
       });
@@ -66,7 +66,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
       });
     } catch (error: any) {
       const errorMessage =
-        error.response?.data?.error || "Registration failed. Please try again.";
+        error.response?.data?.detail || "Registration failed. Please try again.";
       toast.error("Registration Failed", {
         description: errorMessage,
       });
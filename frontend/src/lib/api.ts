@@ -88,6 +88,14 @@ export const authApi = {
   },
 };
 
+// The API takes camelCase request fields and rejects unknown ones
+const toEventBody = (data: CreateEventRequest) => ({
+  name: data.Name,
+  description: data.Description,
+  location: data.Location,
+  dateTime: data.DateTime,
+});
+
 // Events API
 export const eventsApi = {
   getAll: async (): Promise<Event[]> => {
@@ -101,17 +109,26 @@ export const eventsApi = {
   },
 
   create: async (data: CreateEventRequest): Promise<Event> => {
-    const response = await api.post<Event>("/events", data);
+    const response = await api.post<Event>("/events", toEventBody(data));
     return response.data;
   },
 
-  update: async (id: number, data: UpdateEventRequest): Promise<Event> => {
-    const response = await api.put<Event>(`/events/${id}`, data);
+  // Writes must name the version they were based on (the event's ETag)
+  update: async (
+    id: number,
+    version: number,
+    data: UpdateEventRequest
+  ): Promise<Event> => {
+    const response = await api.put<Event>(`/events/${id}`, toEventBody(data), {
+      headers: { "If-Match": `"${version}"` },
+    });
     return response.data;
   },
 
-  delete: async (id: number): Promise<void> => {
-    await api.delete(`/events/${id}`);
+  delete: async (id: number, version: number): Promise<void> => {
+    await api.delete(`/events/${id}`, {
+      headers: { "If-Match": `"${version}"` },
+    });
   },
 
   getAttendees: async (eventId: number): Promise<User[]> => {
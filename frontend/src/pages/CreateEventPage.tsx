@@ -128,7 +128,7 @@ export function CreateEventPage() {
 
       navigate("/events");
     } catch (err: any) {
-      toast.error(err.response?.data?.error || "Failed to save event");
+      toast.error(err.response?.data?.detail || "Failed to save event");
     } finally {
       setIsLoading(false);
     }
@@ -89,7 +89,7 @@ export function EventDetailsPage() {
       toast.success("Successfully joined the event!");
       loadEventDetails();
     } catch (err: any) {
-      toast.error(err.response?.data?.error || "Failed to join event");
+      toast.error(err.response?.data?.detail || "Failed to join event");
     } finally {
       setIsJoining(false);
     }
@@ -115,7 +115,7 @@ export function EventDetailsPage() {
 
     try {
       setIsDeleting(true);
-      await eventsApi.delete(event.Id);
+      await eventsApi.delete(event.Id, event.Version);
       toast.success("Event deleted successfully");
       navigate("/events");
     } catch (err: any) {
@@ -43,7 +43,7 @@ export function EventsPage() {
       // Show more detailed error
       const errorMessage =
         err.response?.data?.message ||
-        err.response?.data?.error ||
+        err.response?.data?.detail ||
         err.message ||
         "Failed to load events. Please try again later.";
       setError(errorMessage);
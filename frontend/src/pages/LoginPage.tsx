@@ -29,7 +29,7 @@ export function LoginPage() {
       await login(email, password);
       navigate(from, { replace: true });
     } catch (err: any) {
-      setError(err.response?.data?.error || "Invalid email or password");
+      setError(err.response?.data?.detail || "Invalid email or password");
     } finally {
       setIsLoading(false);
     }
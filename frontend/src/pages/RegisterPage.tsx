@@ -46,7 +46,7 @@ export function RegisterPage() {
       navigate("/events");
     } catch (err: any) {
       setError(
-        err.response?.data?.error || "Registration failed. Please try again."
+        err.response?.data?.detail || "Registration failed. Please try again."
       );
     } finally {
       setIsLoading(false);
@@ -12,6 +12,7 @@ export interface Event {
   Description: string;
   DateTime: string;
   Location: string;
+  Version: number;
 }
 // Attendee Types
 export interface Attendee {
@@ -59,9 +60,15 @@ export interface AuthContextType {
   isLoading: boolean;
 }
 
-// API Error Response
+// API Error Response (RFC 7807 problem+json)
 export interface ApiError {
-  error: string;
+  type: string;
+  title: string;
+  status: number;
+  detail: string;
+  instance?: string;
+  requestId?: string;
+  fields?: { field: string; code: string; message: string }[];
 }
 
 export interface LoginResponse {